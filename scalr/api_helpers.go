@@ -0,0 +1,175 @@
+package scalr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/scalr/go-scalr"
+	"github.com/svanharmelen/jsonapi"
+)
+
+// apiConfigs keeps the configuration of every client created by
+// newScalrClient, keyed by the client.
+var apiConfigs sync.Map
+
+// newScalrClient creates a new Scalr client and remembers its configuration,
+// so that the endpoints not covered by go-scalr can be called with
+// doAPIRequest using the same address, token and HTTP client.
+func newScalrClient(cfg *scalr.Config) (*scalr.Client, error) {
+	// Layer in the provided config the same way scalr.NewClient does.
+	config := scalr.DefaultConfig()
+	if cfg.Address != "" {
+		config.Address = cfg.Address
+	}
+	if cfg.BasePath != "" {
+		config.BasePath = cfg.BasePath
+	}
+	if cfg.Token != "" {
+		config.Token = cfg.Token
+	}
+	for k, v := range cfg.Headers {
+		config.Headers[k] = v
+	}
+	if cfg.HTTPClient != nil {
+		config.HTTPClient = cfg.HTTPClient
+	}
+
+	// The client shares the HTTP client with doAPIRequest,
+	// so that all the requests go through the same transport.
+	clientConfig := *cfg
+	clientConfig.HTTPClient = config.HTTPClient
+	client, err := scalr.NewClient(&clientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	apiConfigs.Store(client, config)
+	return client, nil
+}
+
+// doAPIRequest sends a request to an endpoint of the Scalr API that is not
+// covered by go-scalr. The in value is JSON:API encoded as the request body,
+// except for a json.RawMessage, which is sent as is.
+// The primary data of the response is decoded into out, which must be
+// a pointer to a struct or to a slice of struct pointers.
+// Errors are reported the same way go-scalr does, so errors.Is works with
+// scalr.ErrResourceNotFound and scalr.ErrUnauthorized.
+func doAPIRequest(ctx context.Context, client *scalr.Client, method, path string, in, out interface{}) error {
+	v, ok := apiConfigs.Load(client)
+	if !ok {
+		return errors.New("the Scalr client is not configured for direct API requests")
+	}
+	config := v.(*scalr.Config)
+
+	baseURL, err := url.Parse(config.Address)
+	if err != nil {
+		return fmt.Errorf("invalid address: %v", err)
+	}
+	if baseURL.Path == "" {
+		baseURL.Path = config.BasePath
+	}
+	if !strings.HasSuffix(baseURL.Path, "/") {
+		baseURL.Path += "/"
+	}
+	u, err := baseURL.Parse(path)
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if raw, ok := in.(json.RawMessage); ok {
+		body = bytes.NewReader(raw)
+	} else if in != nil {
+		buf := bytes.NewBuffer(nil)
+		if err := jsonapi.MarshalPayloadWithoutIncluded(buf, in); err != nil {
+			return err
+		}
+		body = buf
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return err
+	}
+	for k, v := range config.Headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Authorization", "Bearer "+config.Token)
+	req.Header.Set("Accept", "application/vnd.api+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/vnd.api+json")
+	}
+
+	resp, err := config.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkAPIResponse(resp); err != nil {
+		return err
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	dst := reflect.ValueOf(out).Elem()
+	if dst.Kind() != reflect.Slice {
+		return jsonapi.UnmarshalPayload(resp.Body, out)
+	}
+
+	items, err := jsonapi.UnmarshalManyPayload(resp.Body, dst.Type().Elem())
+	if err != nil {
+		return err
+	}
+	result := reflect.MakeSlice(dst.Type(), 0, len(items))
+	for _, item := range items {
+		result = reflect.Append(result, reflect.ValueOf(item))
+	}
+	dst.Set(result)
+
+	return nil
+}
+
+// checkAPIResponse converts an unsuccessful API response to an error.
+func checkAPIResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return scalr.ErrUnauthorized
+	}
+
+	errPayload := &jsonapi.ErrorsPayload{}
+	err := json.NewDecoder(resp.Body).Decode(errPayload)
+	if err != nil || len(errPayload.Errors) == 0 {
+		if resp.StatusCode == http.StatusNotFound {
+			return scalr.ResourceNotFoundError{}
+		}
+		return errors.New(resp.Status)
+	}
+
+	var errs []string
+	for _, e := range errPayload.Errors {
+		if e.Detail == "" {
+			errs = append(errs, e.Title)
+		} else {
+			errs = append(errs, fmt.Sprintf("%s\n\n%s", e.Title, e.Detail))
+		}
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return scalr.ResourceNotFoundError{Message: strings.Join(errs, "\n")}
+	}
+	return errors.New(strings.Join(errs, "\n"))
+}
@@ -0,0 +1,211 @@
+package scalr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed cron expression with the five standard fields:
+// minute, hour, day of month, month and day of week. The schedules of
+// the runs are evaluated in UTC.
+type cronSchedule struct {
+	minutes, hours, days, months, weekdays []bool
+	// anyDay and anyWeekday are set when the day of month or the day of
+	// week is `*`. When both are restricted, a day matches either of them.
+	anyDay, anyWeekday bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{
+		"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC",
+	}},
+	// Both 0 and 7 are Sunday.
+	{name: "day of week", min: 0, max: 7, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
+}
+
+// parseCron parses a cron expression of five space separated fields.
+// A field is `*` or a comma separated list of values and ranges, each
+// optionally with a step, e.g. `*/15`, `1-5`, `0,30` or `MON-FRI`.
+func parseCron(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("expected 5 fields (minute hour day-of-month month day-of-week), got %d", len(fields))
+	}
+
+	values := make([][]bool, len(fields))
+	for i, f := range fields {
+		v, err := cronFields[i].parse(f)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	// Sunday can be written as 0 or 7.
+	weekdays := values[4]
+	weekdays[0] = weekdays[0] || weekdays[7]
+
+	return &cronSchedule{
+		minutes:    values[0],
+		hours:      values[1],
+		days:       values[2],
+		months:     values[3],
+		weekdays:   weekdays[:7],
+		anyDay:     strings.HasPrefix(fields[2], "*"),
+		anyWeekday: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+func (f cronField) parse(expr string) ([]bool, error) {
+	matches := make([]bool, f.max+1)
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepExpr)
+			if err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid step %q in %s field", stepExpr, f.name)
+			}
+		}
+
+		var from, to int
+		switch {
+		case rangeExpr == "*":
+			from, to = f.min, f.max
+		case strings.Contains(rangeExpr, "-"):
+			start, end, _ := strings.Cut(rangeExpr, "-")
+			var err error
+			if from, err = f.value(start); err != nil {
+				return nil, err
+			}
+			if to, err = f.value(end); err != nil {
+				return nil, err
+			}
+			if from > to {
+				return nil, fmt.Errorf("invalid range %q in %s field", rangeExpr, f.name)
+			}
+		default:
+			v, err := f.value(rangeExpr)
+			if err != nil {
+				return nil, err
+			}
+			from, to = v, v
+			if hasStep {
+				to = f.max
+			}
+		}
+
+		for v := from; v <= to; v += step {
+			matches[v] = true
+		}
+	}
+	return matches, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return i + f.min, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s field", s, f.name)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d in %s field", v, f.min, f.max, f.name)
+	}
+	return v, nil
+}
+
+// matchesDay reports whether the schedule fires on the day of t.
+func (c *cronSchedule) matchesDay(t time.Time) bool {
+	if !c.months[int(t.Month())] {
+		return false
+	}
+	day, weekday := c.days[t.Day()], c.weekdays[int(t.Weekday())]
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekday
+	case c.anyWeekday:
+		return day
+	}
+	return day || weekday
+}
+
+// timesOfDay returns the times of the runs on a day the schedule fires,
+// in minutes since midnight in ascending order.
+func (c *cronSchedule) timesOfDay() []int {
+	times := make([]int, 0)
+	for hour, h := range c.hours {
+		for minute, m := range c.minutes {
+			if h && m {
+				times = append(times, hour*60+minute)
+			}
+		}
+	}
+	return times
+}
+
+// cronSearchDays limits the search of the next run. Any valid schedule
+// fires within it, e.g. on the 29th of February.
+const cronSearchDays = 8 * 366
+
+// next returns the first time after t the schedule fires, or false if it
+// never fires, e.g. on the 31st of February.
+func (c *cronSchedule) next(t time.Time) (time.Time, bool) {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	for i := 0; i < cronSearchDays; i, day = i+1, day.AddDate(0, 0, 1) {
+		if !c.matchesDay(day) {
+			continue
+		}
+		for hour := range c.hours {
+			if !c.hours[hour] {
+				continue
+			}
+			for minute := range c.minutes {
+				if !c.minutes[minute] {
+					continue
+				}
+				next := day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+				if !next.Before(t) {
+					return next, true
+				}
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// validateCronExpression checks that the value is empty or a cron
+// expression that fires at some time.
+func validateCronExpression(val interface{}, key string) (warns []string, errs []error) {
+	expr := val.(string)
+	if expr == "" {
+		return
+	}
+	c, err := parseCron(expr)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%s must be a cron expression: %v", key, err))
+		return
+	}
+	if _, ok := c.next(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)); !ok {
+		errs = append(errs, fmt.Errorf("%s never fires: %s", key, expr))
+	}
+	return
+}
@@ -0,0 +1,80 @@
+package scalr
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	for _, expr := range []string{
+		"* * * * *",
+		"*/15 * * * *",
+		"0,30 8-18 * * 1-5",
+		"0 22 * * MON-FRI",
+		"30 3 5 3-5 2",
+		"0 0 1 jan,jul *",
+		"0 12 * * 7",
+		"5/10 0-12/3 */2 * sun",
+	} {
+		if _, err := parseCron(expr); err != nil {
+			t.Errorf("%q: unexpected error: %v", expr, err)
+		}
+	}
+
+	for expr, message := range map[string]string{
+		"* * * *":         "expected 5 fields",
+		"* * * * * *":     "expected 5 fields",
+		"60 * * * *":      "value 60 out of range 0-59 in minute field",
+		"* 24 * * *":      "value 24 out of range 0-23 in hour field",
+		"* * 0 * *":       "value 0 out of range 1-31 in day of month field",
+		"* * * 13 *":      "value 13 out of range 1-12 in month field",
+		"* * * * 8":       "value 8 out of range 0-7 in day of week field",
+		"*/0 * * * *":     `invalid step "0" in minute field`,
+		"* 18-8 * * *":    `invalid range "18-8" in hour field`,
+		"* * * * MON-XYZ": `invalid value "XYZ" in day of week field`,
+		"@daily":          "expected 5 fields",
+	} {
+		_, err := parseCron(expr)
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("%q: expected error %q, got %v", expr, message, err)
+		}
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	// Wednesday.
+	now := time.Date(2024, time.January, 3, 10, 20, 30, 0, time.UTC)
+	for expr, expected := range map[string]string{
+		"* * * * *":        "2024-01-03T10:21:00Z",
+		"*/15 * * * *":     "2024-01-03T10:30:00Z",
+		"0 22 * * MON-FRI": "2024-01-03T22:00:00Z",
+		"0 9 * * 1-5":      "2024-01-04T09:00:00Z",
+		"0 12 * * 0":       "2024-01-07T12:00:00Z",
+		"0 12 * * 7":       "2024-01-07T12:00:00Z",
+		"0 0 1 * *":        "2024-02-01T00:00:00Z",
+		"0 0 29 2 *":       "2024-02-29T00:00:00Z",
+		// The day of month or the day of week.
+		"0 0 15 * FRI": "2024-01-05T00:00:00Z",
+	} {
+		c, err := parseCron(expr)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", expr, err)
+		}
+		next, ok := c.next(now)
+		if !ok || next.Format(time.RFC3339) != expected {
+			t.Errorf("%q: expected %s, got %s", expr, expected, next.Format(time.RFC3339))
+		}
+	}
+
+	c, _ := parseCron("0 0 31 2 *")
+	if _, ok := c.next(now); ok {
+		t.Error("expected the 31st of February to never come")
+	}
+	if _, errs := validateCronExpression("0 0 31 2 *", "apply_schedule"); len(errs) == 0 {
+		t.Error("expected error for a schedule that never fires")
+	}
+	if _, errs := validateCronExpression("", "apply_schedule"); len(errs) != 0 {
+		t.Errorf("unexpected errors for an empty schedule: %v", errs)
+	}
+}
@@ -0,0 +1,147 @@
+package scalr
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/scalr/go-scalr"
+)
+
+// agentStatusOffline is the status of an agent that is not connected.
+// Agents in any other status are connected to Scalr.
+const agentStatusOffline = "offline"
+
+// agentsPageSize is the number of agents requested per page.
+const agentsPageSize = 100
+
+// agent represents an agent of an agent pool with the attributes
+// that go-scalr does not cover.
+type agent struct {
+	ID         string    `jsonapi:"primary,agents"`
+	Name       string    `jsonapi:"attr,name"`
+	Status     string    `jsonapi:"attr,status"`
+	Version    string    `jsonapi:"attr,version"`
+	Hostname   string    `jsonapi:"attr,hostname"`
+	OS         string    `jsonapi:"attr,os"`
+	LastSeenAt time.Time `jsonapi:"attr,last-seen-at,iso8601"`
+}
+
+// listAgentPoolAgents returns all agents of the agent pool.
+func listAgentPoolAgents(ctx context.Context, scalrClient *scalr.Client, agentPoolID string) ([]*agent, error) {
+	agents := make([]*agent, 0)
+	for page := 1; ; page++ {
+		var items []*agent
+		path := fmt.Sprintf("agents?filter[agent-pool]=%s&page[number]=%d&page[size]=%d", agentPoolID, page, agentsPageSize)
+		if err := doAPIRequest(ctx, scalrClient, "GET", path, nil, &items); err != nil {
+			return nil, err
+		}
+		agents = append(agents, items...)
+
+		// Exit the loop when the last page is not full.
+		if len(items) < agentsPageSize {
+			break
+		}
+	}
+	return agents, nil
+}
+
+// countOnlineAgents returns the number of agents that are connected.
+func countOnlineAgents(agents []*agent) int {
+	online := 0
+	for _, a := range agents {
+		if a.Status != agentStatusOffline {
+			online++
+		}
+	}
+	return online
+}
+
+func dataSourceScalrAgentPoolAgents() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceScalrAgentPoolAgentsRead,
+		Schema: map[string]*schema.Schema{
+			"agent_pool_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"agents": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"version": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"hostname": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"os": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"last_seen_at": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"online_count": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceScalrAgentPoolAgentsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+	agentPoolID := d.Get("agent_pool_id").(string)
+
+	log.Printf("[DEBUG] Read agents of agent pool: %s", agentPoolID)
+	agents, err := listAgentPoolAgents(ctx, scalrClient, agentPoolID)
+	if err != nil {
+		return diag.Errorf("Error retrieving agents of agent pool %s: %v", agentPoolID, err)
+	}
+
+	items := make([]map[string]interface{}, 0, len(agents))
+	for _, a := range agents {
+		lastSeenAt := ""
+		if !a.LastSeenAt.IsZero() {
+			lastSeenAt = a.LastSeenAt.UTC().Format(time.RFC3339)
+		}
+		items = append(items, map[string]interface{}{
+			"id":           a.ID,
+			"name":         a.Name,
+			"status":       a.Status,
+			"version":      a.Version,
+			"hostname":     a.Hostname,
+			"os":           a.OS,
+			"last_seen_at": lastSeenAt,
+		})
+	}
+
+	_ = d.Set("agents", items)
+	_ = d.Set("online_count", countOnlineAgents(agents))
+	d.SetId(agentPoolID)
+
+	return nil
+}
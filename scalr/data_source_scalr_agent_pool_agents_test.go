@@ -0,0 +1,141 @@
+package scalr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/scalr/go-scalr"
+)
+
+// testAgent adds an agent to the agent pool of the test API server.
+func testAgent(server *testAPIServer, agentPoolID, name, status string) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.put(&testAPIResource{
+		Type: "agents",
+		ID:   server.newID("agents"),
+		Attributes: map[string]interface{}{
+			"name":         name,
+			"status":       status,
+			"version":      "0.1.30",
+			"hostname":     name + ".internal",
+			"os":           "linux",
+			"last-seen-at": "2023-03-01T10:00:00Z",
+		},
+		Relationships: map[string]*testAPIRelationship{
+			"agent-pool": {Data: map[string]interface{}{"type": "agent-pools", "id": agentPoolID}},
+		},
+	})
+}
+
+func TestDataSourceScalrAgentPoolAgentsRead(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+	client, err := newScalrClient(&scalr.Config{Address: server.Address(), Token: testAPIToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.put(&testAPIResource{Type: "agent-pools", ID: "apool-123", Attributes: map[string]interface{}{}})
+	server.put(&testAPIResource{Type: "agent-pools", ID: "apool-456", Attributes: map[string]interface{}{}})
+
+	testAgent(server, "apool-123", "agent-1", "idle")
+	testAgent(server, "apool-123", "agent-2", agentStatusOffline)
+	testAgent(server, "apool-456", "agent-3", "busy")
+
+	d := schema.TestResourceDataRaw(t, dataSourceScalrAgentPoolAgents().Schema, map[string]interface{}{
+		"agent_pool_id": "apool-123",
+	})
+	if diags := dataSourceScalrAgentPoolAgentsRead(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	agents := d.Get("agents").([]interface{})
+	if len(agents) != 2 {
+		t.Fatalf("expected 2 agents, got %v", agents)
+	}
+	first := agents[0].(map[string]interface{})
+	delete(first, "id")
+	expected := map[string]interface{}{
+		"name":         "agent-1",
+		"status":       "idle",
+		"version":      "0.1.30",
+		"hostname":     "agent-1.internal",
+		"os":           "linux",
+		"last_seen_at": "2023-03-01T10:00:00Z",
+	}
+	if !reflect.DeepEqual(first, expected) {
+		t.Fatalf("expected %v, got %v", expected, first)
+	}
+	if online := d.Get("online_count").(int); online != 1 {
+		t.Fatalf("expected 1 online agent, got %d", online)
+	}
+
+	// All pages are read.
+	for i := 0; i < agentsPageSize; i++ {
+		testAgent(server, "apool-456", fmt.Sprintf("agent-%d", i), "idle")
+	}
+	agents2, err := listAgentPoolAgents(ctx, client, "apool-456")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(agents2) != agentsPageSize+1 {
+		t.Fatalf("expected %d agents, got %d", agentsPageSize+1, len(agents2))
+	}
+}
+
+func TestWorkspace_requireOnlineAgents(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+	client, err := newScalrClient(&scalr.Config{Address: server.Address(), Token: testAPIToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.put(&testAPIResource{Type: "agent-pools", ID: "apool-123", Attributes: map[string]interface{}{}})
+	testAgent(server, "apool-123", "agent-1", agentStatusOffline)
+
+	env, err := client.Environments.Create(ctx, scalr.EnvironmentCreateOptions{
+		Name:    scalr.String("test-env"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating environment: %v", err)
+	}
+
+	r := resourceScalrWorkspace()
+	create := func(name string, require bool) error {
+		d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+			"name":                  name,
+			"environment_id":        env.ID,
+			"agent_pool_id":         "apool-123",
+			"require_online_agents": require,
+		})
+		if diags := r.CreateContext(ctx, d, client); diags.HasError() {
+			return fmt.Errorf("%s", diags[0].Summary)
+		}
+		return nil
+	}
+
+	err = create("ws-1", true)
+	if err == nil || !strings.Contains(err.Error(), "Agent pool apool-123 has no online agents (1 agents registered)") {
+		t.Fatalf("expected error for a pool without online agents, got %v", err)
+	}
+	wl, err := client.Workspaces.List(ctx, scalr.WorkspaceListOptions{Environment: scalr.String(env.ID)})
+	if err != nil {
+		t.Fatalf("error listing workspaces: %v", err)
+	}
+	if len(wl.Items) != 0 {
+		t.Fatalf("expected no workspace to be created, got %d", len(wl.Items))
+	}
+
+	if err := create("ws-2", false); err != nil {
+		t.Fatalf("unexpected error without require_online_agents: %v", err)
+	}
+
+	testAgent(server, "apool-123", "agent-2", "idle")
+	if err := create("ws-3", true); err != nil {
+		t.Fatalf("unexpected error with an online agent: %v", err)
+	}
+}
@@ -30,7 +30,7 @@ func dataSourceScalrCurrentAccountRead(ctx context.Context, d *schema.ResourceDa
 
 	accID, ok := getDefaultScalrAccountID()
 	if !ok {
-		log.Printf("[DEBUG] %s is not set", currentAccountIDEnvVar)
+		log.Printf("[DEBUG] Neither the account_id provider argument nor %s is set", currentAccountIDEnvVar)
 		return diag.Errorf("Current account is not set")
 	}
 
@@ -0,0 +1,265 @@
+package scalr
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/scalr/go-scalr"
+)
+
+// Scopes of the variables, from the outermost one.
+const (
+	variableScopeAccount     = "account"
+	variableScopeEnvironment = "environment"
+	variableScopeWorkspace   = "workspace"
+)
+
+var variableScopes = []string{variableScopeAccount, variableScopeEnvironment, variableScopeWorkspace}
+
+func dataSourceScalrEffectiveVariables() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceScalrEffectiveVariablesRead,
+		Schema: map[string]*schema.Schema{
+			"workspace_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"category": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ValidateFunc: validation.StringInSlice(
+					[]string{string(scalr.CategoryEnv), string(scalr.CategoryTerraform), string(scalr.CategoryShell)},
+					false,
+				),
+			},
+			"keys": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"environment_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"account_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"variables": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"key": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"category": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"value": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"hcl": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"sensitive": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"final": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"scope": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"overridden_scopes": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"blocked_scopes": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"override_blocked": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// effectiveVariable is the result of the inheritance of the variables
+// with the same key and category.
+type effectiveVariable struct {
+	variable *scalr.Variable
+	scope    string
+	// overridden are the outer scopes whose variables were overridden.
+	overridden []string
+	// blocked are the inner scopes whose variables were ignored,
+	// because a variable on an outer scope is final.
+	blocked []string
+}
+
+// variableScope returns the scope of the variable in the workspace,
+// or false if the variable is defined on another environment or workspace.
+func variableScope(v *scalr.Variable, workspaceID, environmentID string) (string, bool) {
+	switch {
+	case v.Workspace != nil:
+		return variableScopeWorkspace, v.Workspace.ID == workspaceID
+	case v.Environment != nil:
+		return variableScopeEnvironment, v.Environment.ID == environmentID
+	default:
+		return variableScopeAccount, true
+	}
+}
+
+// resolveEffectiveVariables applies the inheritance to the variables of the
+// workspace scopes: a variable on an inner scope overrides the one with the
+// same key and category on an outer scope, unless the outer one is final.
+func resolveEffectiveVariables(byScope map[string][]*scalr.Variable) []*effectiveVariable {
+	effective := make(map[string]*effectiveVariable)
+	for _, scope := range variableScopes {
+		for _, v := range byScope[scope] {
+			id := string(v.Category) + "/" + v.Key
+			current, ok := effective[id]
+			switch {
+			case !ok:
+				effective[id] = &effectiveVariable{variable: v, scope: scope}
+			case current.variable.Final:
+				current.blocked = append(current.blocked, scope)
+			default:
+				current.overridden = append(current.overridden, current.scope)
+				current.variable = v
+				current.scope = scope
+			}
+		}
+	}
+
+	result := make([]*effectiveVariable, 0, len(effective))
+	for _, e := range effective {
+		result = append(result, e)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].variable.Key != result[j].variable.Key {
+			return result[i].variable.Key < result[j].variable.Key
+		}
+		return result[i].variable.Category < result[j].variable.Category
+	})
+	return result
+}
+
+func dataSourceScalrEffectiveVariablesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+	workspaceID := d.Get("workspace_id").(string)
+
+	log.Printf("[DEBUG] Read effective variables of workspace: %s", workspaceID)
+	workspace, err := scalrClient.Workspaces.ReadByID(ctx, workspaceID)
+	if err != nil {
+		return diag.Errorf("Error retrieving workspace %s: %v", workspaceID, err)
+	}
+	if workspace.Environment == nil {
+		return diag.Errorf("Error retrieving environment of workspace %s", workspaceID)
+	}
+	environmentID := workspace.Environment.ID
+	environment, err := scalrClient.Environments.Read(ctx, environmentID)
+	if err != nil {
+		return diag.Errorf("Error retrieving environment %s: %v", environmentID, err)
+	}
+	if environment.Account == nil {
+		return diag.Errorf("Error retrieving account of environment %s", environmentID)
+	}
+	accountID := environment.Account.ID
+
+	filters := scalr.VariableFilter{Account: scalr.String(accountID)}
+	if category, ok := d.GetOk("category"); ok {
+		filters.Category = scalr.String(category.(string))
+	}
+	if keysI, ok := d.GetOk("keys"); ok {
+		keys := make([]string, 0)
+		for _, keyI := range keysI.(*schema.Set).List() {
+			keys = append(keys, keyI.(string))
+		}
+		if len(keys) > 0 {
+			filters.Key = scalr.String("in:" + strings.Join(keys, ","))
+		}
+	}
+
+	byScope := make(map[string][]*scalr.Variable)
+	options := scalr.VariableListOptions{Filter: &filters}
+	for {
+		vl, err := scalrClient.Variables.List(ctx, options)
+		if err != nil {
+			return diag.Errorf("Error retrieving variables: %v", err)
+		}
+
+		for _, v := range vl.Items {
+			if scope, ok := variableScope(v, workspaceID, environmentID); ok {
+				byScope[scope] = append(byScope[scope], v)
+			}
+		}
+
+		// Exit the loop when we've seen all pages.
+		if vl.CurrentPage >= vl.TotalPages {
+			break
+		}
+
+		// Update the page number to get the next page.
+		options.PageNumber = vl.NextPage
+	}
+
+	variables := make([]map[string]interface{}, 0)
+	for _, e := range resolveEffectiveVariables(byScope) {
+		v := e.variable
+		value := v.Value
+		if v.Sensitive {
+			value = ""
+		}
+		variables = append(variables, map[string]interface{}{
+			"id":                v.ID,
+			"key":               v.Key,
+			"category":          string(v.Category),
+			"value":             value,
+			"hcl":               v.HCL,
+			"sensitive":         v.Sensitive,
+			"final":             v.Final,
+			"description":       v.Description,
+			"scope":             e.scope,
+			"overridden_scopes": e.overridden,
+			"blocked_scopes":    e.blocked,
+			"override_blocked":  len(e.blocked) > 0,
+		})
+	}
+
+	_ = d.Set("environment_id", environmentID)
+	_ = d.Set("account_id", accountID)
+	_ = d.Set("variables", variables)
+	filterKey := fmt.Sprintf("%s/%v", d.Get("category"), d.Get("keys").(*schema.Set).List())
+	d.SetId(fmt.Sprintf("%s/%d", workspaceID, schema.HashString(filterKey)))
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package scalr
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/scalr/go-scalr"
+)
+
+func TestAccScalrEffectiveVariablesDataSource_validation(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data scalr_effective_variables test {
+  workspace_id = "ws-123"
+  category     = "secret"
+}`,
+				ExpectError: regexp.MustCompile(`expected category to be one of`),
+			},
+		},
+	})
+}
+
+func TestDataSourceScalrEffectiveVariablesRead(t *testing.T) {
+	client := testScalrClient(t)
+
+	env, err := client.Environments.Create(ctx, scalr.EnvironmentCreateOptions{
+		Name:    scalr.String("test-env"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating environment: %v", err)
+	}
+	otherEnv, err := client.Environments.Create(ctx, scalr.EnvironmentCreateOptions{
+		Name:    scalr.String("other-env"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating environment: %v", err)
+	}
+	workspaces := make([]*scalr.Workspace, 0)
+	for _, name := range []string{"test-ws", "other-ws"} {
+		ws, err := client.Workspaces.Create(ctx, scalr.WorkspaceCreateOptions{
+			Name:        scalr.String(name),
+			Environment: &scalr.Environment{ID: env.ID},
+		})
+		if err != nil {
+			t.Fatalf("error creating workspace: %v", err)
+		}
+		workspaces = append(workspaces, ws)
+	}
+	ws := workspaces[0]
+
+	for _, v := range []struct {
+		key       string
+		category  scalr.CategoryType
+		final     bool
+		sensitive bool
+		env       *scalr.Environment
+		ws        *scalr.Workspace
+	}{
+		// Overridden on every scope.
+		{key: "region", category: scalr.CategoryTerraform},
+		{key: "region", category: scalr.CategoryTerraform, env: env},
+		{key: "region", category: scalr.CategoryTerraform, env: env, ws: ws},
+		// Final on the account, so the workspace cannot override it.
+		{key: "owner", category: scalr.CategoryTerraform, final: true},
+		{key: "owner", category: scalr.CategoryTerraform, env: env, ws: ws},
+		// The same key in another category is another variable.
+		{key: "owner", category: scalr.CategoryShell, env: env},
+		{key: "token", category: scalr.CategoryShell, sensitive: true, env: env},
+		// Variables of other scopes do not apply.
+		{key: "region", category: scalr.CategoryShell, env: otherEnv},
+		{key: "token", category: scalr.CategoryShell, env: env, ws: workspaces[1]},
+	} {
+		_, err := client.Variables.Create(ctx, scalr.VariableCreateOptions{
+			Key:         scalr.String(v.key),
+			Value:       scalr.String("value"),
+			Category:    scalr.Category(v.category),
+			Final:       scalr.Bool(v.final),
+			Sensitive:   scalr.Bool(v.sensitive),
+			Account:     &scalr.Account{ID: defaultAccount},
+			Environment: v.env,
+			Workspace:   v.ws,
+		})
+		if err != nil {
+			t.Fatalf("error creating variable: %v", err)
+		}
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceScalrEffectiveVariables().Schema, map[string]interface{}{
+		"workspace_id": ws.ID,
+	})
+	if diags := dataSourceScalrEffectiveVariablesRead(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if d.Get("environment_id") != env.ID || d.Get("account_id") != defaultAccount {
+		t.Fatalf("unexpected scopes %s and %s", d.Get("environment_id"), d.Get("account_id"))
+	}
+
+	type result struct {
+		key, category, scope string
+		overridden, blocked  []interface{}
+		final, sensitive     bool
+	}
+	got := make([]result, 0)
+	for _, v := range d.Get("variables").([]interface{}) {
+		v := v.(map[string]interface{})
+		got = append(got, result{
+			key:        v["key"].(string),
+			category:   v["category"].(string),
+			scope:      v["scope"].(string),
+			overridden: v["overridden_scopes"].([]interface{}),
+			blocked:    v["blocked_scopes"].([]interface{}),
+			final:      v["final"].(bool),
+			sensitive:  v["sensitive"].(bool),
+		})
+		if v["override_blocked"].(bool) != (len(v["blocked_scopes"].([]interface{})) > 0) {
+			t.Fatalf("override_blocked does not match blocked_scopes: %v", v)
+		}
+		if v["sensitive"].(bool) && v["value"] != "" {
+			t.Fatalf("expected no value for a sensitive variable: %v", v)
+		}
+	}
+	expected := []result{
+		{"owner", "shell", "environment", []interface{}{}, []interface{}{}, false, false},
+		{"owner", "terraform", "account", []interface{}{}, []interface{}{"workspace"}, true, false},
+		{"region", "terraform", "workspace", []interface{}{"account", "environment"}, []interface{}{}, false, false},
+		{"token", "shell", "environment", []interface{}{}, []interface{}{}, false, true},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+
+	// The filters limit the variables that are resolved.
+	d = schema.TestResourceDataRaw(t, dataSourceScalrEffectiveVariables().Schema, map[string]interface{}{
+		"workspace_id": ws.ID,
+		"category":     "terraform",
+		"keys":         []interface{}{"owner"},
+	})
+	if diags := dataSourceScalrEffectiveVariablesRead(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if variables := d.Get("variables").([]interface{}); len(variables) != 1 {
+		t.Fatalf("expected 1 variable, got %v", variables)
+	}
+}
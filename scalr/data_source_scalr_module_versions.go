@@ -0,0 +1,148 @@
+package scalr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/scalr/go-scalr"
+)
+
+func dataSourceScalrModuleVersions() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceScalrModuleVersionsRead,
+		Schema: map[string]*schema.Schema{
+			"module_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"version_constraint": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(string)
+					if _, err := version.NewConstraint(v); err != nil {
+						errs = append(errs, fmt.Errorf("%s must be a version constraint, e.g. \"~> 2.1\", got: %s", key, v))
+					}
+					return
+				},
+			},
+			"versions": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"version": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"is_root_module": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"latest_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"latest_version": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceScalrModuleVersionsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	moduleID := d.Get("module_id").(string)
+	constraintRaw := d.Get("version_constraint").(string)
+
+	var constraints version.Constraints
+	if constraintRaw != "" {
+		var err error
+		constraints, err = version.NewConstraint(constraintRaw)
+		if err != nil {
+			return diag.Errorf("Invalid version constraint %q: %v", constraintRaw, err)
+		}
+	}
+
+	type listed struct {
+		mv      *scalr.ModuleVersion
+		version *version.Version
+	}
+	matched := make([]listed, 0)
+
+	options := scalr.ModuleVersionListOptions{Module: moduleID}
+	for {
+		log.Printf("[DEBUG] List versions of module %s, page %d", moduleID, options.PageNumber)
+		mvl, err := scalrClient.ModuleVersions.List(ctx, options)
+		if err != nil {
+			if errors.Is(err, scalr.ErrResourceNotFound) {
+				return diag.Errorf("Could not find module %s", moduleID)
+			}
+			return diag.Errorf("Error retrieving module versions: %v", err)
+		}
+
+		for _, mv := range mvl.Items {
+			v, err := version.NewSemver(mv.Version)
+			if err != nil {
+				log.Printf("[DEBUG] Skip module version %s with invalid version %q: %v", mv.ID, mv.Version, err)
+				continue
+			}
+			if constraints != nil && !constraints.Check(v) {
+				continue
+			}
+			matched = append(matched, listed{mv: mv, version: v})
+		}
+
+		if mvl.Pagination == nil || mvl.CurrentPage >= mvl.TotalPages {
+			break
+		}
+		options.PageNumber = mvl.NextPage
+	}
+
+	// The newest versions first.
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].version.GreaterThan(matched[j].version)
+	})
+
+	versions := make([]map[string]interface{}, 0, len(matched))
+	var latestID, latestVersion string
+	for _, m := range matched {
+		versions = append(versions, map[string]interface{}{
+			"id":             m.mv.ID,
+			"version":        m.mv.Version,
+			"status":         string(m.mv.Status),
+			"is_root_module": m.mv.IsRootModule,
+		})
+		// Only a successfully processed version can be used by workspaces.
+		if latestID == "" && m.mv.Status == scalr.ModuleVersionOk {
+			latestID = m.mv.ID
+			latestVersion = m.mv.Version
+		}
+	}
+
+	_ = d.Set("versions", versions)
+	_ = d.Set("latest_id", latestID)
+	_ = d.Set("latest_version", latestVersion)
+	d.SetId(fmt.Sprintf("%s/%d", moduleID, schema.HashString(constraintRaw)))
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package scalr
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/scalr/go-scalr"
+)
+
+func TestAccScalrModuleVersionsDataSource_validation(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data scalr_module_versions test {
+  module_id          = "mod-123"
+  version_constraint = "newest"
+}`,
+				ExpectError: regexp.MustCompile("version_constraint must be a version constraint"),
+			},
+		},
+	})
+}
+
+func TestDataSourceScalrModuleVersionsRead(t *testing.T) {
+	client := testScalrClient(t)
+	module := testModule(t, client, "")
+
+	r := resourceScalrModuleVersion()
+	ids := make(map[string]string)
+	for _, tag := range []string{"v1.9.0", "v2.1.0", "v2.1.3", "v2.2.0", "v2.3.0-invalid", "v3.0.0"} {
+		d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+			"module_id": module.ID,
+			"tag":       tag,
+		})
+		_ = r.CreateContext(ctx, d, client)
+		ids[d.Get("version").(string)] = d.Id()
+	}
+
+	for _, tc := range []struct {
+		constraint string
+		versions   []string
+		latest     string
+	}{
+		{"", []string{"3.0.0", "2.3.0-invalid", "2.2.0", "2.1.3", "2.1.0", "1.9.0"}, "3.0.0"},
+		{"~> 2.1", []string{"2.2.0", "2.1.3", "2.1.0"}, "2.2.0"},
+		{"~> 2.1.0", []string{"2.1.3", "2.1.0"}, "2.1.3"},
+		{"= 2.3.0-invalid", []string{"2.3.0-invalid"}, ""},
+		{"> 3.0.0", []string{}, ""},
+	} {
+		t.Run(fmt.Sprintf("constraint %q", tc.constraint), func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceScalrModuleVersions().Schema, map[string]interface{}{
+				"module_id":          module.ID,
+				"version_constraint": tc.constraint,
+			})
+			if diags := dataSourceScalrModuleVersionsRead(ctx, d, client); diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			versions := d.Get("versions").([]interface{})
+			if len(versions) != len(tc.versions) {
+				t.Fatalf("expected versions %v, got %v", tc.versions, versions)
+			}
+			for i, v := range versions {
+				v := v.(map[string]interface{})
+				if v["version"] != tc.versions[i] || v["id"] != ids[tc.versions[i]] {
+					t.Fatalf("expected version %s at %d, got %v", tc.versions[i], i, v)
+				}
+			}
+
+			if latest := d.Get("latest_version").(string); latest != tc.latest {
+				t.Fatalf("expected the latest version %q, got %q", tc.latest, latest)
+			}
+			if id := d.Get("latest_id").(string); id != ids[tc.latest] {
+				t.Fatalf("expected the latest version ID %q, got %q", ids[tc.latest], id)
+			}
+		})
+	}
+
+	// A workspace can be pinned to the latest matching version.
+	d := schema.TestResourceDataRaw(t, dataSourceScalrModuleVersions().Schema, map[string]interface{}{
+		"module_id":          module.ID,
+		"version_constraint": "~> 2.1",
+	})
+	if diags := dataSourceScalrModuleVersionsRead(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	mv, err := client.ModuleVersions.Read(ctx, d.Get("latest_id").(string))
+	if err != nil {
+		t.Fatalf("error reading module version: %v", err)
+	}
+	if mv.Status != scalr.ModuleVersionOk {
+		t.Fatalf("expected the latest version to be processed, got %s", mv.Status)
+	}
+}
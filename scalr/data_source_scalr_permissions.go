@@ -0,0 +1,241 @@
+package scalr
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/scalr/go-scalr"
+)
+
+// permissionsPageSize is the number of permissions requested per page.
+const permissionsPageSize = 100
+
+// permission represents a permission of the catalog with the attributes
+// that go-scalr does not cover.
+type permission struct {
+	ID          string `jsonapi:"primary,permissions"`
+	Description string `jsonapi:"attr,description"`
+	ObjectType  string `jsonapi:"attr,object-type"`
+}
+
+// listPermissions returns the catalog of the permissions the server knows,
+// sorted by ID.
+func listPermissions(ctx context.Context, scalrClient *scalr.Client) ([]*permission, error) {
+	permissions := make([]*permission, 0)
+	for page := 1; ; page++ {
+		var items []*permission
+		p := fmt.Sprintf("permissions?page[number]=%d&page[size]=%d", page, permissionsPageSize)
+		if err := doAPIRequest(ctx, scalrClient, "GET", p, nil, &items); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, items...)
+
+		// Exit the loop when the last page is not full.
+		if len(items) < permissionsPageSize {
+			break
+		}
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].ID < permissions[j].ID })
+	return permissions, nil
+}
+
+func validatePermissionPattern(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	if v == "" {
+		errs = append(errs, fmt.Errorf("%s must not be empty", key))
+	} else if _, err := path.Match(v, ""); err != nil {
+		errs = append(errs, fmt.Errorf("%s has an invalid pattern %q: %v", key, v, err))
+	}
+	return
+}
+
+// expandPermissionPatterns returns the permissions of the catalog that match
+// any of the patterns, e.g. `workspaces:*`. Each pattern must match at least
+// one permission, so that a typo is not silently ignored.
+func expandPermissionPatterns(catalog []*permission, patterns []string) ([]*permission, error) {
+	matched := make([]*permission, 0)
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		found := false
+		for _, p := range catalog {
+			if ok, _ := path.Match(pattern, p.ID); !ok {
+				continue
+			}
+			found = true
+			if !seen[p.ID] {
+				seen[p.ID] = true
+				matched = append(matched, p)
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("pattern %q does not match any permission", pattern)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	return matched, nil
+}
+
+// unknownPermissions returns the IDs that are not in the catalog,
+// with the closest known permission suggested for a likely typo.
+// A wildcard permission, e.g. `*:update`, is known if it matches
+// any permission of the catalog.
+func unknownPermissions(catalog []*permission, ids []string) []string {
+	known := make(map[string]bool, len(catalog))
+	for _, p := range catalog {
+		known[p.ID] = true
+	}
+
+	unknown := make([]string, 0)
+	for _, id := range ids {
+		if known[id] {
+			continue
+		}
+		if strings.Contains(id, "*") {
+			if _, err := expandPermissionPatterns(catalog, []string{id}); err == nil {
+				continue
+			}
+		}
+		if suggestion := closestPermission(catalog, id); suggestion != "" {
+			unknown = append(unknown, fmt.Sprintf("%s (did you mean %s?)", id, suggestion))
+		} else {
+			unknown = append(unknown, id)
+		}
+	}
+	return unknown
+}
+
+// closestPermission returns the permission of the catalog within a few
+// edits of the ID, or an empty string if there is none.
+func closestPermission(catalog []*permission, id string) string {
+	closest, best := "", len(id)/3+1
+	for _, p := range catalog {
+		if d := editDistance(id, p.ID); d < best {
+			closest, best = p.ID, d
+		}
+	}
+	return closest
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+func dataSourceScalrPermissions() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceScalrPermissionsRead,
+		Schema: map[string]*schema.Schema{
+			"permissions_from": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validatePermissionPattern,
+				},
+			},
+			"object_type": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"permissions": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"object_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceScalrPermissionsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	log.Printf("[DEBUG] Read permissions catalog")
+	catalog, err := listPermissions(ctx, scalrClient)
+	if err != nil {
+		return diag.Errorf("Error retrieving permissions: %v", err)
+	}
+
+	patterns := make([]string, 0)
+	for _, v := range d.Get("permissions_from").([]interface{}) {
+		patterns = append(patterns, v.(string))
+	}
+	if len(patterns) > 0 {
+		catalog, err = expandPermissionPatterns(catalog, patterns)
+		if err != nil {
+			return diag.Errorf("Error expanding permissions_from: %v", err)
+		}
+	}
+
+	objectType := d.Get("object_type").(string)
+	ids := make([]string, 0)
+	permissions := make([]map[string]interface{}, 0)
+	for _, p := range catalog {
+		if objectType != "" && p.ObjectType != objectType {
+			continue
+		}
+		ids = append(ids, p.ID)
+		permissions = append(permissions, map[string]interface{}{
+			"id":          p.ID,
+			"description": p.Description,
+			"object_type": p.ObjectType,
+		})
+	}
+
+	_ = d.Set("ids", ids)
+	_ = d.Set("permissions", permissions)
+	d.SetId(fmt.Sprintf("%d", schema.HashString(objectType+strings.Join(patterns, ","))))
+
+	return nil
+}
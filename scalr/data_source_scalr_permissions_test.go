@@ -0,0 +1,98 @@
+package scalr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/scalr/go-scalr"
+)
+
+// testPermissions adds a permissions catalog to the test API server.
+func testPermissions(server *testAPIServer) {
+	for id, objectType := range map[string]string{
+		"workspaces:read":   "workspaces",
+		"workspaces:update": "workspaces",
+		"workspaces:delete": "workspaces",
+		"runs:create":       "runs",
+		"accounts:update":   "accounts",
+	} {
+		server.put(&testAPIResource{Type: "permissions", ID: id, Attributes: map[string]interface{}{
+			"description": "Allows " + id,
+			"object-type": objectType,
+		}})
+	}
+}
+
+func TestDataSourceScalrPermissionsRead(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+	client, err := newScalrClient(&scalr.Config{Address: server.Address(), Token: testAPIToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testPermissions(server)
+	r := dataSourceScalrPermissions()
+
+	read := func(raw map[string]interface{}) (*schema.ResourceData, error) {
+		d := schema.TestResourceDataRaw(t, r.Schema, raw)
+		if diags := r.ReadContext(ctx, d, client); diags.HasError() {
+			return nil, fmt.Errorf("%s", diags[0].Summary)
+		}
+		return d, nil
+	}
+	idsOf := func(d *schema.ResourceData) []string {
+		ids := make([]string, 0)
+		for _, v := range d.Get("ids").([]interface{}) {
+			ids = append(ids, v.(string))
+		}
+		return ids
+	}
+
+	d, err := read(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ids := idsOf(d); len(ids) != 5 || ids[0] != "accounts:update" {
+		t.Fatalf("expected the whole catalog sorted by ID, got %v", ids)
+	}
+	first := d.Get("permissions").([]interface{})[0].(map[string]interface{})
+	expected := map[string]interface{}{
+		"id":          "accounts:update",
+		"description": "Allows accounts:update",
+		"object_type": "accounts",
+	}
+	if !reflect.DeepEqual(first, expected) {
+		t.Fatalf("expected %v, got %v", expected, first)
+	}
+
+	d, err = read(map[string]interface{}{"permissions_from": []interface{}{"workspaces:*", "runs:create"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedIDs := []string{"runs:create", "workspaces:delete", "workspaces:read", "workspaces:update"}
+	if ids := idsOf(d); !reflect.DeepEqual(ids, expectedIDs) {
+		t.Fatalf("expected %v, got %v", expectedIDs, ids)
+	}
+
+	d, err = read(map[string]interface{}{"object_type": "runs"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ids := idsOf(d); !reflect.DeepEqual(ids, []string{"runs:create"}) {
+		t.Fatalf("expected the permissions of runs, got %v", ids)
+	}
+
+	_, err = read(map[string]interface{}{"permissions_from": []interface{}{"workspace:*"}})
+	if err == nil || !strings.Contains(err.Error(), `pattern "workspace:*" does not match any permission`) {
+		t.Fatalf("expected error for a pattern without matches, got %v", err)
+	}
+
+	for _, pattern := range []string{"", "workspaces:[*"} {
+		if _, errs := validatePermissionPattern(pattern, "permissions_from"); len(errs) == 0 {
+			t.Errorf("%q: expected error", pattern)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package scalr
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/scalr/go-scalr"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Formats of the files parsed by scalr_variables_from_file.
+const (
+	variablesFileFormatTfvars     = "tfvars"
+	variablesFileFormatTfvarsJSON = "tfvars_json"
+	variablesFileFormatDotenv     = "dotenv"
+)
+
+// defaultSensitiveKeyPatterns match the keys of the variables
+// that are likely to hold secrets.
+var defaultSensitiveKeyPatterns = []string{
+	`(?i)passw(or)?d`,
+	`(?i)secret`,
+	`(?i)token`,
+	`(?i)api_?key`,
+	`(?i)private_?key`,
+	`(?i)access_?key`,
+	`(?i)credential`,
+}
+
+// fileVariable is a variable parsed from a file.
+type fileVariable struct {
+	key   string
+	value string
+	hcl   bool
+}
+
+func dataSourceScalrVariablesFromFile() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceScalrVariablesFromFileRead,
+		Schema: map[string]*schema.Schema{
+			"filename": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"filename", "content"},
+			},
+			"content": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				RequiredWith: []string{"format"},
+			},
+			"format": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ValidateFunc: validation.StringInSlice(
+					[]string{variablesFileFormatTfvars, variablesFileFormatTfvarsJSON, variablesFileFormatDotenv},
+					false,
+				),
+			},
+			"sensitive_key_patterns": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringIsValidRegExp,
+				},
+			},
+			"variables": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"value": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"category": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"hcl": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"sensitive": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"values": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"sensitive_values": {
+				Type:      schema.TypeMap,
+				Computed:  true,
+				Sensitive: true,
+				Elem:      &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+// variablesFileFormat infers the format of the file from its name.
+func variablesFileFormat(filename string) (string, error) {
+	base := strings.ToLower(filename[strings.LastIndexAny(filename, `/\`)+1:])
+	switch {
+	case strings.HasSuffix(base, ".tfvars"):
+		return variablesFileFormatTfvars, nil
+	case strings.HasSuffix(base, ".tfvars.json"):
+		return variablesFileFormatTfvarsJSON, nil
+	case base == ".env" || strings.HasPrefix(base, ".env.") || strings.HasSuffix(base, ".env"):
+		return variablesFileFormatDotenv, nil
+	}
+	return "", fmt.Errorf("cannot infer the format of %s, set the format attribute", filename)
+}
+
+// parseTfvars parses the variables of a .tfvars file in the native or the JSON
+// syntax. Strings, numbers and booleans are kept as is, the other values are
+// kept as HCL code.
+func parseTfvars(filename string, content []byte, jsonSyntax bool) ([]fileVariable, error) {
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if jsonSyntax {
+		file, diags = hcljson.Parse(content, filename)
+	} else {
+		file, diags = hclsyntax.ParseConfig(content, filename, hcl.InitialPos)
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	variables := make([]fileVariable, 0, len(attrs))
+	for key, attr := range attrs {
+		value, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+
+		v := fileVariable{key: key}
+		switch {
+		case value.IsNull():
+			continue
+		case value.Type() == cty.String:
+			v.value = value.AsString()
+		case value.Type() == cty.Number:
+			v.value = value.AsBigFloat().Text('f', -1)
+		case value.Type() == cty.Bool:
+			v.value = fmt.Sprint(value.True())
+		default:
+			// Lists, maps and objects are kept as they are written in the file.
+			v.value = string(attr.Expr.Range().SliceBytes(content))
+			v.hcl = true
+		}
+		variables = append(variables, v)
+	}
+	return variables, nil
+}
+
+// parseDotenv parses the variables of a dotenv file. Lines may start with
+// `export`, values may be quoted with single quotes, which are taken literally,
+// or double quotes, which support the \n, \t, \" and \\ escapes. Unquoted values
+// end at a ` #` comment.
+func parseDotenv(filename string, content []byte) ([]fileVariable, error) {
+	keyPattern := regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+	variables := make([]fileVariable, 0)
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimSpace(strings.TrimPrefix(text, "export "))
+
+		key, value, ok := strings.Cut(text, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !keyPattern.MatchString(key) {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", filename, line)
+		}
+		value = strings.TrimSpace(value)
+
+		switch {
+		case strings.HasPrefix(value, `'`):
+			end := strings.Index(value[1:], `'`)
+			if end < 0 {
+				return nil, fmt.Errorf("%s:%d: unterminated single-quoted value", filename, line)
+			}
+			value = value[1 : end+1]
+		case strings.HasPrefix(value, `"`):
+			var b strings.Builder
+			closed := false
+			for i := 1; i < len(value) && !closed; i++ {
+				switch c := value[i]; {
+				case c == '"':
+					closed = true
+				case c == '\\' && i+1 < len(value):
+					i++
+					switch value[i] {
+					case 'n':
+						b.WriteByte('\n')
+					case 't':
+						b.WriteByte('\t')
+					default:
+						b.WriteByte(value[i])
+					}
+				default:
+					b.WriteByte(c)
+				}
+			}
+			if !closed {
+				return nil, fmt.Errorf("%s:%d: unterminated double-quoted value", filename, line)
+			}
+			value = b.String()
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+
+		variables = append(variables, fileVariable{key: key, value: value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return variables, nil
+}
+
+func dataSourceScalrVariablesFromFileRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	filename := d.Get("filename").(string)
+	format := d.Get("format").(string)
+
+	var content []byte
+	if filename != "" {
+		log.Printf("[DEBUG] Read variables from file: %s", filename)
+		var err error
+		content, err = os.ReadFile(filename)
+		if err != nil {
+			return diag.Errorf("Error reading variables file: %v", err)
+		}
+		if format == "" {
+			format, err = variablesFileFormat(filename)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+		}
+	} else {
+		filename = "content"
+		content = []byte(d.Get("content").(string))
+	}
+
+	patterns := defaultSensitiveKeyPatterns
+	if v, ok := d.GetOk("sensitive_key_patterns"); ok {
+		patterns = make([]string, 0)
+		for _, p := range v.([]interface{}) {
+			patterns = append(patterns, p.(string))
+		}
+	}
+	sensitiveKeys := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return diag.Errorf("Invalid sensitive key pattern %q: %v", p, err)
+		}
+		sensitiveKeys = append(sensitiveKeys, re)
+	}
+
+	var parsed []fileVariable
+	var err error
+	category := scalr.CategoryTerraform
+	switch format {
+	case variablesFileFormatTfvars:
+		parsed, err = parseTfvars(filename, content, false)
+	case variablesFileFormatTfvarsJSON:
+		parsed, err = parseTfvars(filename, content, true)
+	case variablesFileFormatDotenv:
+		parsed, err = parseDotenv(filename, content)
+		category = scalr.CategoryShell
+	}
+	if err != nil {
+		return diag.Errorf("Error parsing variables: %v", err)
+	}
+	sort.Slice(parsed, func(i, j int) bool { return parsed[i].key < parsed[j].key })
+
+	variables := make([]map[string]interface{}, 0, len(parsed))
+	values := make(map[string]interface{})
+	sensitiveValues := make(map[string]interface{})
+	for _, v := range parsed {
+		sensitive := false
+		for _, re := range sensitiveKeys {
+			sensitive = sensitive || re.MatchString(v.key)
+		}
+
+		value := v.value
+		if sensitive {
+			// The value is only exposed in the sensitive map, so that the list
+			// and the map of values can be used in for_each.
+			sensitiveValues[v.key] = value
+			value = ""
+		} else {
+			values[v.key] = value
+		}
+		variables = append(variables, map[string]interface{}{
+			"key":       v.key,
+			"value":     value,
+			"category":  string(category),
+			"hcl":       v.hcl,
+			"sensitive": sensitive,
+		})
+	}
+
+	_ = d.Set("format", format)
+	_ = d.Set("variables", variables)
+	_ = d.Set("values", values)
+	_ = d.Set("sensitive_values", sensitiveValues)
+	d.SetId(fmt.Sprintf("%d", schema.HashString(format+string(content))))
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package scalr
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAccScalrVariablesFromFileDataSource_validation(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data scalr_variables_from_file test {
+  content = "foo = 1"
+}`,
+				ExpectError: regexp.MustCompile(`"content": all of .content,format. must be specified`),
+			},
+			{
+				Config: `
+data scalr_variables_from_file test {
+  filename = "vars.yaml"
+  content  = "foo = 1"
+  format   = "tfvars"
+}`,
+				ExpectError: regexp.MustCompile(`only one of .content,filename. can be specified`),
+			},
+		},
+	})
+}
+
+func TestParseTfvars(t *testing.T) {
+	content := `
+region   = "us-east-1"
+replicas = 3
+ratio    = 0.5
+enabled  = true
+unset    = null
+zones    = ["a", "b"]
+tags = {
+  team = "core"
+}
+`
+	variables, err := parseTfvars("test.tfvars", []byte(content), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := make(map[string]fileVariable)
+	for _, v := range variables {
+		got[v.key] = v
+	}
+	expected := map[string]fileVariable{
+		"region":   {key: "region", value: "us-east-1"},
+		"replicas": {key: "replicas", value: "3"},
+		"ratio":    {key: "ratio", value: "0.5"},
+		"enabled":  {key: "enabled", value: "true"},
+		"zones":    {key: "zones", value: `["a", "b"]`, hcl: true},
+		"tags":     {key: "tags", value: "{\n  team = \"core\"\n}", hcl: true},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+
+	variables, err = parseTfvars("test.tfvars.json", []byte(`{"region": "us-east-1", "zones": ["a", "b"]}`), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(variables) != 2 {
+		t.Fatalf("expected 2 variables, got %v", variables)
+	}
+
+	if _, err := parseTfvars("test.tfvars", []byte(`region = var.region`), false); err == nil {
+		t.Fatal("expected error for a reference to a variable")
+	}
+}
+
+func TestParseDotenv(t *testing.T) {
+	content := `
+# Comment
+export REGION=us-east-1
+NAME = app # inline comment
+EMPTY=
+GREETING="hello \"world\"\n"
+RAW='a\nb # not a comment'
+URL=http://example.com/#anchor
+`
+	variables, err := parseDotenv(".env", []byte(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []fileVariable{
+		{key: "REGION", value: "us-east-1"},
+		{key: "NAME", value: "app"},
+		{key: "EMPTY", value: ""},
+		{key: "GREETING", value: "hello \"world\"\n"},
+		{key: "RAW", value: `a\nb # not a comment`},
+		{key: "URL", value: "http://example.com/#anchor"},
+	}
+	if !reflect.DeepEqual(variables, expected) {
+		t.Fatalf("expected %v, got %v", expected, variables)
+	}
+
+	for _, content := range []string{"NOVALUE", "1KEY=value", `KEY="open`, "KEY='open"} {
+		if _, err := parseDotenv(".env", []byte(content)); err == nil {
+			t.Fatalf("expected error for %q", content)
+		}
+	}
+}
+
+func TestVariablesFileFormat(t *testing.T) {
+	for filename, expected := range map[string]string{
+		"prod.tfvars":              variablesFileFormatTfvars,
+		"config/prod.auto.tfvars":  variablesFileFormatTfvars,
+		"prod.tfvars.json":         variablesFileFormatTfvarsJSON,
+		".env":                     variablesFileFormatDotenv,
+		"config/.env.production":   variablesFileFormatDotenv,
+		"config/production.env":    variablesFileFormatDotenv,
+		"config/production.yaml":   "",
+		"config/environment/vars":  "",
+		"config/.env.d/production": "",
+	} {
+		format, err := variablesFileFormat(filename)
+		if format != expected || (expected == "") != (err != nil) {
+			t.Errorf("%s: expected format %q, got %q, %v", filename, expected, format, err)
+		}
+	}
+}
+
+func TestDataSourceScalrVariablesFromFileRead(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	err := os.WriteFile(filename, []byte("DB_HOST=db.local\nDB_PASSWORD=hunter2\nGITHUB_TOKEN=ghp_123\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceScalrVariablesFromFile().Schema, map[string]interface{}{
+		"filename": filename,
+	})
+	if diags := dataSourceScalrVariablesFromFileRead(ctx, d, nil); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if format := d.Get("format").(string); format != variablesFileFormatDotenv {
+		t.Fatalf("expected the dotenv format, got %q", format)
+	}
+	expected := []interface{}{
+		map[string]interface{}{"key": "DB_HOST", "value": "db.local", "category": "shell", "hcl": false, "sensitive": false},
+		map[string]interface{}{"key": "DB_PASSWORD", "value": "", "category": "shell", "hcl": false, "sensitive": true},
+		map[string]interface{}{"key": "GITHUB_TOKEN", "value": "", "category": "shell", "hcl": false, "sensitive": true},
+	}
+	if variables := d.Get("variables").([]interface{}); !reflect.DeepEqual(variables, expected) {
+		t.Fatalf("expected %v, got %v", expected, variables)
+	}
+	if value := d.Get("values.DB_HOST").(string); value != "db.local" {
+		t.Fatalf("expected value %q of DB_HOST, got %q", "db.local", value)
+	}
+	if values := d.Get("values").(map[string]interface{}); len(values) != 1 {
+		t.Fatalf("expected only the values of the non-sensitive variables, got %v", values)
+	}
+	expectedSensitive := map[string]interface{}{"DB_PASSWORD": "hunter2", "GITHUB_TOKEN": "ghp_123"}
+	if values := d.Get("sensitive_values").(map[string]interface{}); !reflect.DeepEqual(values, expectedSensitive) {
+		t.Fatalf("expected sensitive values %v, got %v", expectedSensitive, values)
+	}
+
+	// Custom patterns replace the default ones.
+	d = schema.TestResourceDataRaw(t, dataSourceScalrVariablesFromFile().Schema, map[string]interface{}{
+		"content":                "db_host = \"db.local\"\nmy_token = \"abc\"\nnodes = [1, 2]\n",
+		"format":                 "tfvars",
+		"sensitive_key_patterns": []interface{}{"^db_"},
+	})
+	if diags := dataSourceScalrVariablesFromFileRead(ctx, d, nil); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	expected = []interface{}{
+		map[string]interface{}{"key": "db_host", "value": "", "category": "terraform", "hcl": false, "sensitive": true},
+		map[string]interface{}{"key": "my_token", "value": "abc", "category": "terraform", "hcl": false, "sensitive": false},
+		map[string]interface{}{"key": "nodes", "value": "[1, 2]", "category": "terraform", "hcl": true, "sensitive": false},
+	}
+	if variables := d.Get("variables").([]interface{}); !reflect.DeepEqual(variables, expected) {
+		t.Fatalf("expected %v, got %v", expected, variables)
+	}
+	expectedValues := map[string]interface{}{"my_token": "abc", "nodes": "[1, 2]"}
+	if values := d.Get("values").(map[string]interface{}); !reflect.DeepEqual(values, expectedValues) {
+		t.Fatalf("expected values %v, got %v", expectedValues, values)
+	}
+}
@@ -50,26 +50,14 @@ func dataSourceScalrWorkspaceIDsRead(ctx context.Context, d *schema.ResourceData
 	// Create a map to store workspace IDs
 	ids := make(map[string]string, len(names))
 
-	options := scalr.WorkspaceListOptions{Environment: &environmentID}
-	for {
-		wl, err := scalrClient.Workspaces.List(ctx, options)
-		if err != nil {
-			return diag.Errorf("Error retrieving workspaces: %v", err)
-		}
-
-		for _, w := range wl.Items {
-			if names["*"] || names[w.Name] {
-				ids[w.Name] = w.ID
-			}
-		}
-
-		// Exit the loop when we've seen all pages.
-		if wl.CurrentPage >= wl.TotalPages {
-			break
-		}
-
-		// Update the page number to get the next page.
-		options.PageNumber = wl.NextPage
+	workspaces, err := listWorkspaces(ctx, scalrClient, environmentID, func(w *scalr.Workspace) bool {
+		return names["*"] || names[w.Name]
+	})
+	if err != nil {
+		return diag.Errorf("Error retrieving workspaces: %v", err)
+	}
+	for _, w := range workspaces {
+		ids[w.Name] = w.ID
 	}
 
 	_ = d.Set("ids", ids)
@@ -0,0 +1,190 @@
+package scalr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/scalr/go-scalr"
+)
+
+// stateVersion represents a version of the workspace state.
+type stateVersion struct {
+	ID      string                `jsonapi:"primary,state-versions"`
+	Serial  int                   `jsonapi:"attr,serial"`
+	Outputs []*stateVersionOutput `jsonapi:"attr,outputs"`
+}
+
+// stateVersionOutput represents a root module output of a state version.
+type stateVersionOutput struct {
+	Name      string      `json:"name"`
+	Value     interface{} `json:"value"`
+	Sensitive bool        `json:"sensitive"`
+}
+
+func dataSourceScalrWorkspaceOutputs() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceScalrWorkspaceOutputsRead,
+		Schema: map[string]*schema.Schema{
+			"workspace_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				AtLeastOneOf: []string{"name"},
+			},
+			"name": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"workspace_id"},
+				RequiredWith:  []string{"environment_id"},
+			},
+			"environment_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"workspace_id"},
+				RequiredWith:  []string{"name"},
+			},
+			"state_version_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"serial": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"outputs": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"value": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"sensitive": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"values": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"sensitive_values": {
+				Type:      schema.TypeMap,
+				Computed:  true,
+				Sensitive: true,
+				Elem:      &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+// outputValueType returns the type of a JSON decoded output value.
+func outputValueType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64, json.Number:
+		return "number"
+	case bool:
+		return "bool"
+	case []interface{}:
+		return "list"
+	default:
+		return "map"
+	}
+}
+
+func dataSourceScalrWorkspaceOutputsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	workspaceID := d.Get("workspace_id").(string)
+	if workspaceID == "" {
+		var err error
+		workspaceID, err = fetchWorkspaceID(
+			ctx, fmt.Sprintf("%s/%s", d.Get("environment_id").(string), d.Get("name").(string)), scalrClient,
+		)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	log.Printf("[DEBUG] Read configuration of workspace: %s", workspaceID)
+	workspace, err := scalrClient.Workspaces.ReadByID(ctx, workspaceID)
+	if err != nil {
+		if errors.Is(err, scalr.ErrResourceNotFound) {
+			return diag.Errorf("Could not find workspace %s", workspaceID)
+		}
+		return diag.Errorf("Error retrieving workspace: %v", err)
+	}
+
+	log.Printf("[DEBUG] Read current state version of workspace: %s", workspaceID)
+	sv := &stateVersion{}
+	err = doAPIRequest(ctx, scalrClient, "GET", fmt.Sprintf("workspaces/%s/current-state-version", workspaceID), nil, sv)
+	if err != nil {
+		if errors.Is(err, scalr.ErrResourceNotFound) {
+			return diag.Errorf("Workspace %s has no state yet", workspaceID)
+		}
+		return diag.Errorf("Error retrieving current state version of workspace %s: %v", workspaceID, err)
+	}
+
+	outputs := make([]map[string]interface{}, 0)
+	values := make(map[string]interface{})
+	sensitiveValues := make(map[string]interface{})
+	for _, o := range sv.Outputs {
+		encoded, err := json.Marshal(o.Value)
+		if err != nil {
+			return diag.Errorf("Error encoding value of output %s: %v", o.Name, err)
+		}
+
+		output := map[string]interface{}{
+			"name":      o.Name,
+			"type":      outputValueType(o.Value),
+			"value":     string(encoded),
+			"sensitive": o.Sensitive,
+		}
+		if o.Sensitive {
+			// Sensitive values are kept out of the plain attributes,
+			// so that they are not shown in the plan output.
+			output["value"] = ""
+			sensitiveValues[o.Name] = string(encoded)
+		} else {
+			values[o.Name] = string(encoded)
+		}
+		outputs = append(outputs, output)
+	}
+
+	_ = d.Set("workspace_id", workspace.ID)
+	_ = d.Set("name", workspace.Name)
+	if workspace.Environment != nil {
+		_ = d.Set("environment_id", workspace.Environment.ID)
+	}
+	_ = d.Set("state_version_id", sv.ID)
+	_ = d.Set("serial", sv.Serial)
+	_ = d.Set("outputs", outputs)
+	_ = d.Set("values", values)
+	_ = d.Set("sensitive_values", sensitiveValues)
+	d.SetId(workspace.ID)
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package scalr
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/scalr/go-scalr"
+)
+
+func TestAccScalrWorkspaceOutputsDataSource_noState(t *testing.T) {
+	rInt := GetRandomInteger()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccScalrWorkspaceOutputsDataSourceByIDConfig(rInt),
+				ExpectError: regexp.MustCompile("has no state yet"),
+			},
+			{
+				Config:      testAccScalrWorkspaceOutputsDataSourceByNameConfig(rInt),
+				ExpectError: regexp.MustCompile("has no state yet"),
+			},
+		},
+	})
+}
+
+func TestAccScalrWorkspaceOutputsDataSource_validation(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      `data scalr_workspace_outputs test {}`,
+				ExpectError: regexp.MustCompile("one of `name,workspace_id` must be specified"),
+			},
+			{
+				Config: `
+data scalr_workspace_outputs test {
+  name = "test"
+}`,
+				ExpectError: regexp.MustCompile("all of `environment_id,name` must be specified"),
+			},
+		},
+	})
+}
+
+// testStateVersionCreateOptions is used to seed state versions
+// in the local test API.
+type testStateVersionCreateOptions struct {
+	ID      string                `jsonapi:"primary,state-versions"`
+	Serial  int                   `jsonapi:"attr,serial"`
+	Outputs []*stateVersionOutput `jsonapi:"attr,outputs"`
+
+	Workspace *scalr.Workspace `jsonapi:"relation,workspace"`
+}
+
+func TestDataSourceScalrWorkspaceOutputsRead(t *testing.T) {
+	client := testScalrClient(t)
+
+	env, err := client.Environments.Create(ctx, scalr.EnvironmentCreateOptions{
+		Name:    scalr.String("test-env"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating environment: %v", err)
+	}
+	ws, err := client.Workspaces.Create(ctx, scalr.WorkspaceCreateOptions{
+		Name:        scalr.String("upstream"),
+		Environment: &scalr.Environment{ID: env.ID},
+	})
+	if err != nil {
+		t.Fatalf("error creating workspace: %v", err)
+	}
+
+	for serial, outputs := range [][]*stateVersionOutput{
+		{{Name: "outdated", Value: "value"}},
+		{
+			{Name: "string", Value: "value"},
+			{Name: "number", Value: 42},
+			{Name: "bool", Value: true},
+			{Name: "list", Value: []interface{}{"a", "b"}},
+			{Name: "map", Value: map[string]interface{}{"key": "value"}},
+			{Name: "secret", Value: "s3cr3t", Sensitive: true},
+		},
+	} {
+		err := doAPIRequest(ctx, client, "POST", "state-versions", &testStateVersionCreateOptions{
+			Serial:    serial + 1,
+			Outputs:   outputs,
+			Workspace: &scalr.Workspace{ID: ws.ID},
+		}, nil)
+		if err != nil {
+			t.Fatalf("error creating state version: %v", err)
+		}
+	}
+
+	expected := map[string][2]string{
+		"string": {"string", `"value"`},
+		"number": {"number", `42`},
+		"bool":   {"bool", `true`},
+		"list":   {"list", `["a","b"]`},
+		"map":    {"map", `{"key":"value"}`},
+		"secret": {"string", ""},
+	}
+
+	for name, raw := range map[string]map[string]interface{}{
+		"by ID":   {"workspace_id": ws.ID},
+		"by name": {"environment_id": env.ID, "name": "upstream"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceScalrWorkspaceOutputs().Schema, raw)
+			if diags := dataSourceScalrWorkspaceOutputsRead(ctx, d, client); diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			if d.Id() != ws.ID {
+				t.Fatalf("expected ID %s, got %s", ws.ID, d.Id())
+			}
+			if v := d.Get("environment_id").(string); v != env.ID {
+				t.Fatalf("expected environment %s, got %s", env.ID, v)
+			}
+			if v := d.Get("serial").(int); v != 2 {
+				t.Fatalf("expected the current state version, got serial %d", v)
+			}
+
+			outputs := d.Get("outputs").([]interface{})
+			if len(outputs) != len(expected) {
+				t.Fatalf("expected %d outputs, got %d", len(expected), len(outputs))
+			}
+			values := d.Get("values").(map[string]interface{})
+			if _, ok := values["secret"]; ok {
+				t.Fatal("expected the sensitive output to be left out of values")
+			}
+			sensitiveValues := d.Get("sensitive_values").(map[string]interface{})
+			if len(sensitiveValues) != 1 || sensitiveValues["secret"] != `"s3cr3t"` {
+				t.Fatalf("expected only the sensitive output in sensitive_values, got %v", sensitiveValues)
+			}
+			for _, o := range outputs {
+				o := o.(map[string]interface{})
+				name := o["name"].(string)
+				want, ok := expected[name]
+				if !ok {
+					t.Fatalf("unexpected output %s", name)
+				}
+				if o["type"] != want[0] || o["value"] != want[1] {
+					t.Fatalf("expected output %s to be %s %s, got %s %s", name, want[0], want[1], o["type"], o["value"])
+				}
+				if o["sensitive"] != (name == "secret") {
+					t.Fatalf("unexpected sensitive flag of output %s", name)
+				}
+				if name != "secret" && values[name] != want[1] {
+					t.Fatalf("expected value of %s to be %s, got %v", name, want[1], values[name])
+				}
+			}
+		})
+	}
+}
+
+func testAccScalrWorkspaceOutputsDataSourceByIDConfig(rInt int) string {
+	return fmt.Sprintf(`
+resource scalr_environment test {
+  name       = "test-env-outputs-%[1]d"
+  account_id = "%[2]s"
+}
+
+resource scalr_workspace test {
+  name           = "test-ws-outputs-%[1]d"
+  environment_id = scalr_environment.test.id
+}
+
+data scalr_workspace_outputs test {
+  workspace_id = scalr_workspace.test.id
+}`, rInt, defaultAccount)
+}
+
+func testAccScalrWorkspaceOutputsDataSourceByNameConfig(rInt int) string {
+	return fmt.Sprintf(`
+resource scalr_environment test {
+  name       = "test-env-outputs-%[1]d"
+  account_id = "%[2]s"
+}
+
+resource scalr_workspace test {
+  name           = "test-ws-outputs-%[1]d"
+  environment_id = scalr_environment.test.id
+}
+
+data scalr_workspace_outputs test {
+  environment_id = scalr_environment.test.id
+  name           = scalr_workspace.test.name
+}`, rInt, defaultAccount)
+}
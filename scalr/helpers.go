@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/scalr/go-scalr"
@@ -167,7 +168,34 @@ func InterfaceArrToTagRelationArr(arr []interface{}) []*scalr.TagRelation {
 	return tags
 }
 
+// providerAccountID holds the account_id argument of the provider.
+// Schema default functions have no access to the provider meta, so the
+// value is kept here. Terraform runs a separate provider process for every
+// provider configuration, so aliased providers do not share the value.
+var providerAccountID struct {
+	sync.RWMutex
+	value      string
+	configured bool
+}
+
+// setProviderAccountID records the account_id argument of the configured provider.
+func setProviderAccountID(accountID string) {
+	providerAccountID.Lock()
+	defer providerAccountID.Unlock()
+	providerAccountID.value = accountID
+	providerAccountID.configured = true
+}
+
+// getDefaultScalrAccountID returns the account ID used when a resource
+// does not set it: the account_id argument of the provider, then the
+// SCALR_ACCOUNT_ID environment variable.
 func getDefaultScalrAccountID() (string, bool) {
+	providerAccountID.RLock()
+	accountID := providerAccountID.value
+	providerAccountID.RUnlock()
+	if accountID != "" {
+		return accountID, true
+	}
 	if v := os.Getenv(currentAccountIDEnvVar); v != "" {
 		return v, true
 	}
@@ -178,7 +206,31 @@ func scalrAccountIDDefaultFunc() (interface{}, error) {
 	if accID, ok := getDefaultScalrAccountID(); ok {
 		return accID, nil
 	}
-	return nil, errors.New("Default value for `account_id` could not be computed." +
-		"\nIf you are using Scalr Provider for local runs, please set the attribute in resources explicitly," +
-		"\nor export `SCALR_ACCOUNT_ID` environment variable prior the run.")
+
+	providerAccountID.RLock()
+	configured := providerAccountID.configured
+	providerAccountID.RUnlock()
+	if !configured {
+		// The provider is not configured during the validation,
+		// so its account_id is not known yet.
+		return nil, nil
+	}
+
+	return nil, errDefaultAccountID
+}
+
+var errDefaultAccountID = errors.New("Default value for `account_id` could not be computed." +
+	"\nThe value was looked for in:" +
+	"\n  - the `account_id` attribute of the resource or data source," +
+	"\n  - the `account_id` argument of the provider," +
+	"\n  - the `" + currentAccountIDEnvVar + "` environment variable." +
+	"\nIf you are using Scalr Provider for local runs, please set one of them prior the run.")
+
+func stringInSlice(s string, slice []string) bool {
+	for _, v := range slice {
+		if v == s {
+			return true
+		}
+	}
+	return false
 }
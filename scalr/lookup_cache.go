@@ -0,0 +1,269 @@
+package scalr
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/scalr/go-scalr"
+)
+
+// lookupCacheTTL is how long a cached lookup is reused. A provider instance
+// lives for a single Terraform operation, so the results only need to
+// survive a plan or an apply.
+const lookupCacheTTL = 5 * time.Minute
+
+// Kinds of the cached lookups.
+const (
+	lookupKindEnvironments = "environments"
+	lookupKindEndpoints    = "endpoints"
+	lookupKindWebhooks     = "webhooks"
+	lookupKindWorkspaces   = "workspaces"
+)
+
+// lookupCache is a read-through cache of the API lookups. Concurrent calls
+// with the same key share a single request. Errors are not cached.
+// The cached values are shared between the callers and must not be modified.
+type lookupCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]map[string]*lookupEntry
+	// generation is bumped on every write, so that a lookup started
+	// before the write does not store a stale result.
+	generation int
+}
+
+type lookupEntry struct {
+	done    chan struct{}
+	value   interface{}
+	err     error
+	expires time.Time
+}
+
+func newLookupCache(ttl time.Duration) *lookupCache {
+	return &lookupCache{
+		ttl:     ttl,
+		entries: make(map[string]map[string]*lookupEntry),
+	}
+}
+
+// get returns the cached result of the lookup of the kind with the key,
+// calling fetch if there is none.
+func (c *lookupCache) get(kind, key string, fetch func() (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	if c.entries[kind] == nil {
+		c.entries[kind] = make(map[string]*lookupEntry)
+	}
+	if e, ok := c.entries[kind][key]; ok {
+		select {
+		case <-e.done:
+			if time.Now().Before(e.expires) {
+				c.mu.Unlock()
+				log.Printf("[DEBUG] Lookup cache hit: %s %s", kind, key)
+				return e.value, nil
+			}
+		default:
+			c.mu.Unlock()
+			<-e.done
+			if e.err != nil {
+				// The shared lookup failed, try on our own.
+				return fetch()
+			}
+			return e.value, nil
+		}
+	}
+	e := &lookupEntry{done: make(chan struct{})}
+	c.entries[kind][key] = e
+	generation := c.generation
+	c.mu.Unlock()
+
+	e.value, e.err = fetch()
+	e.expires = time.Now().Add(c.ttl)
+
+	c.mu.Lock()
+	if e.err != nil || c.generation != generation {
+		if c.entries[kind][key] == e {
+			delete(c.entries[kind], key)
+		}
+	}
+	c.mu.Unlock()
+	close(e.done)
+
+	return e.value, e.err
+}
+
+// invalidate drops all the cached lookups.
+func (c *lookupCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) > 0 {
+		log.Printf("[DEBUG] Lookup cache invalidated")
+	}
+	c.entries = make(map[string]map[string]*lookupEntry)
+	c.generation++
+}
+
+// lookupKey builds the cache key from the arguments of a lookup.
+func lookupKey(method string, args ...interface{}) string {
+	b, err := json.Marshal(args)
+	if err != nil {
+		// Should not happen with the option structs, but never share
+		// the results in this case.
+		return method + ":" + time.Now().String()
+	}
+	return method + ":" + string(b)
+}
+
+// lookupCacheTransport is an http.RoundTripper that drops the cached lookups
+// after every write request. A write may change any of the looked up
+// resources, e.g. linking a policy group changes the environment,
+// so the cache is never reused across a write.
+type lookupCacheTransport struct {
+	transport http.RoundTripper
+	cache     *lookupCache
+}
+
+func (t *lookupCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		defer t.cache.invalidate()
+	}
+	return t.transport.RoundTrip(req)
+}
+
+// enableLookupCache replaces the services of the client used for the lookups
+// with the caching ones, and wraps the transport of its HTTP client to
+// invalidate the cache on writes. The client must be created by newScalrClient.
+func enableLookupCache(client *scalr.Client, ttl time.Duration) {
+	v, ok := apiConfigs.Load(client)
+	if !ok {
+		log.Printf("[WARN] Lookup cache is not enabled: the Scalr client has no known configuration")
+		return
+	}
+	httpClient := v.(*scalr.Config).HTTPClient
+
+	cache := newLookupCache(ttl)
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	httpClient.Transport = &lookupCacheTransport{transport: transport, cache: cache}
+
+	client.Environments = &cachedEnvironments{Environments: client.Environments, cache: cache}
+	client.Endpoints = &cachedEndpoints{Endpoints: client.Endpoints, cache: cache}
+	client.Webhooks = &cachedWebhooks{Webhooks: client.Webhooks, cache: cache}
+	client.Workspaces = &cachedWorkspaces{Workspaces: client.Workspaces, cache: cache}
+}
+
+type cachedEnvironments struct {
+	scalr.Environments
+	cache *lookupCache
+}
+
+func (s *cachedEnvironments) List(ctx context.Context, options scalr.EnvironmentListOptions) (*scalr.EnvironmentList, error) {
+	v, err := s.cache.get(lookupKindEnvironments, lookupKey("List", options), func() (interface{}, error) {
+		return s.Environments.List(ctx, options)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*scalr.EnvironmentList), nil
+}
+
+func (s *cachedEnvironments) Read(ctx context.Context, environmentID string) (*scalr.Environment, error) {
+	v, err := s.cache.get(lookupKindEnvironments, lookupKey("Read", environmentID), func() (interface{}, error) {
+		return s.Environments.Read(ctx, environmentID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*scalr.Environment), nil
+}
+
+type cachedEndpoints struct {
+	scalr.Endpoints
+	cache *lookupCache
+}
+
+func (s *cachedEndpoints) List(ctx context.Context, options scalr.EndpointListOptions) (*scalr.EndpointList, error) {
+	v, err := s.cache.get(lookupKindEndpoints, lookupKey("List", options), func() (interface{}, error) {
+		return s.Endpoints.List(ctx, options)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*scalr.EndpointList), nil
+}
+
+func (s *cachedEndpoints) Read(ctx context.Context, endpoint string) (*scalr.Endpoint, error) {
+	v, err := s.cache.get(lookupKindEndpoints, lookupKey("Read", endpoint), func() (interface{}, error) {
+		return s.Endpoints.Read(ctx, endpoint)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*scalr.Endpoint), nil
+}
+
+type cachedWebhooks struct {
+	scalr.Webhooks
+	cache *lookupCache
+}
+
+func (s *cachedWebhooks) List(ctx context.Context, options scalr.WebhookListOptions) (*scalr.WebhookList, error) {
+	v, err := s.cache.get(lookupKindWebhooks, lookupKey("List", options), func() (interface{}, error) {
+		return s.Webhooks.List(ctx, options)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*scalr.WebhookList), nil
+}
+
+func (s *cachedWebhooks) Read(ctx context.Context, webhook string) (*scalr.Webhook, error) {
+	v, err := s.cache.get(lookupKindWebhooks, lookupKey("Read", webhook), func() (interface{}, error) {
+		return s.Webhooks.Read(ctx, webhook)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*scalr.Webhook), nil
+}
+
+type cachedWorkspaces struct {
+	scalr.Workspaces
+	cache *lookupCache
+}
+
+func (s *cachedWorkspaces) List(ctx context.Context, options scalr.WorkspaceListOptions) (*scalr.WorkspaceList, error) {
+	v, err := s.cache.get(lookupKindWorkspaces, lookupKey("List", options), func() (interface{}, error) {
+		return s.Workspaces.List(ctx, options)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*scalr.WorkspaceList), nil
+}
+
+func (s *cachedWorkspaces) Read(ctx context.Context, environmentID, workspaceName string) (*scalr.Workspace, error) {
+	v, err := s.cache.get(lookupKindWorkspaces, lookupKey("Read", environmentID, workspaceName), func() (interface{}, error) {
+		return s.Workspaces.Read(ctx, environmentID, workspaceName)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*scalr.Workspace), nil
+}
+
+func (s *cachedWorkspaces) ReadByID(ctx context.Context, workspaceID string) (*scalr.Workspace, error) {
+	v, err := s.cache.get(lookupKindWorkspaces, lookupKey("ReadByID", workspaceID), func() (interface{}, error) {
+		return s.Workspaces.ReadByID(ctx, workspaceID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*scalr.Workspace), nil
+}
@@ -0,0 +1,216 @@
+package scalr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/scalr/go-scalr"
+)
+
+// countingTransport counts the requests by method.
+type countingTransport struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.counts[req.Method]++
+	t.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (t *countingTransport) count(method string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.counts[method]
+}
+
+func TestLookupCache_client(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+
+	transport := &countingTransport{counts: make(map[string]int)}
+	client, err := newScalrClient(&scalr.Config{
+		Address:    server.Address(),
+		Token:      testAPIToken,
+		HTTPClient: &http.Client{Transport: transport},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	enableLookupCache(client, time.Minute)
+
+	env, err := client.Environments.Create(ctx, scalr.EnvironmentCreateOptions{
+		Name:    scalr.String("cached"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating environment: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		found, err := GetEnvironmentByName(ctx, GetEnvironmentByNameOptions{
+			Name:    scalr.String("cached"),
+			Account: scalr.String(defaultAccount),
+		}, client)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if found.ID != env.ID {
+			t.Fatalf("expected environment %s, got %s", env.ID, found.ID)
+		}
+	}
+	if n := transport.count(http.MethodGet); n != 1 {
+		t.Fatalf("expected 1 request, got %d", n)
+	}
+
+	// A write invalidates the cached lookups.
+	_, err = client.Environments.Update(ctx, env.ID, scalr.EnvironmentUpdateOptions{
+		Name: scalr.String("renamed"),
+	})
+	if err != nil {
+		t.Fatalf("error updating environment: %v", err)
+	}
+	_, err = GetEnvironmentByName(ctx, GetEnvironmentByNameOptions{
+		Name:    scalr.String("cached"),
+		Account: scalr.String(defaultAccount),
+	}, client)
+	if err == nil {
+		t.Fatal("expected the renamed environment not to be found")
+	}
+	if n := transport.count(http.MethodGet); n != 2 {
+		t.Fatalf("expected 2 requests, got %d", n)
+	}
+
+	// So does a write of another type, as it may change a relationship
+	// of the looked up resources.
+	if _, err := client.Environments.Read(ctx, env.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.Tags.Create(ctx, scalr.TagCreateOptions{
+		Name:    scalr.String("tag"),
+		Account: &scalr.Account{ID: defaultAccount},
+	}); err != nil {
+		t.Fatalf("error creating tag: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.Environments.Read(ctx, env.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n := transport.count(http.MethodGet); n != 4 {
+		t.Fatalf("expected 4 requests, got %d", n)
+	}
+
+	// Including the direct API requests.
+	if err := doAPIRequest(ctx, client, http.MethodDelete, "tags/"+env.ID, nil, nil); err == nil {
+		t.Fatal("expected the tag not to be found")
+	}
+	if _, err := client.Environments.Read(ctx, env.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := transport.count(http.MethodGet); n != 5 {
+		t.Fatalf("expected 5 requests, got %d", n)
+	}
+}
+
+type stubEnvironments struct {
+	scalr.Environments
+	calls int32
+	delay time.Duration
+	err   error
+}
+
+func (s *stubEnvironments) Read(_ context.Context, environmentID string) (*scalr.Environment, error) {
+	atomic.AddInt32(&s.calls, 1)
+	time.Sleep(s.delay)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &scalr.Environment{ID: environmentID}, nil
+}
+
+func TestLookupCache_singleFlight(t *testing.T) {
+	stub := &stubEnvironments{delay: 100 * time.Millisecond}
+	envs := &cachedEnvironments{Environments: stub, cache: newLookupCache(time.Minute)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			env, err := envs.Read(ctx, "env-123")
+			if err != nil || env.ID != "env-123" {
+				t.Errorf("unexpected result: %v, %v", env, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if stub.calls != 1 {
+		t.Fatalf("expected 1 call, got %d", stub.calls)
+	}
+}
+
+func TestLookupCache_expiry(t *testing.T) {
+	stub := &stubEnvironments{}
+	envs := &cachedEnvironments{Environments: stub, cache: newLookupCache(50 * time.Millisecond)}
+
+	for i := 0; i < 2; i++ {
+		if _, err := envs.Read(ctx, "env-123"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, err := envs.Read(ctx, "env-123"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if stub.calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", stub.calls)
+	}
+}
+
+func TestLookupCache_errors(t *testing.T) {
+	stub := &stubEnvironments{err: errors.New("boom")}
+	envs := &cachedEnvironments{Environments: stub, cache: newLookupCache(time.Minute)}
+
+	for i := 0; i < 2; i++ {
+		if _, err := envs.Read(ctx, "env-123"); err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+
+	if stub.calls != 2 {
+		t.Fatalf("expected the errors not to be cached, got %d calls", stub.calls)
+	}
+}
+
+func TestLookupCache_invalidateInFlight(t *testing.T) {
+	stub := &stubEnvironments{delay: 100 * time.Millisecond}
+	cache := newLookupCache(time.Minute)
+	envs := &cachedEnvironments{Environments: stub, cache: cache}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = envs.Read(ctx, "env-123")
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cache.invalidate()
+	<-done
+
+	// The result of the lookup started before the write is not stored.
+	if _, err := envs.Read(ctx, "env-123"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stub.calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", stub.calls)
+	}
+}
@@ -0,0 +1,205 @@
+package scalr
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// oidcRefreshBefore is how long before the expiry an access token
+// obtained with an OIDC token is refreshed. Short-lived tokens are
+// refreshed after 80% of their lifetime.
+const oidcRefreshBefore = 5 * time.Minute
+
+// oidcTokenSource exchanges an OIDC ID token issued by a CI system
+// for a Scalr access token of a service account, and refreshes
+// the access token before it expires.
+type oidcTokenSource struct {
+	// exchangeURL is the URL of the token exchange endpoint.
+	exchangeURL         string
+	serviceAccountEmail string
+	// idToken returns the current OIDC ID token. It is read on every
+	// exchange, as the CI system may rotate it.
+	idToken func() (string, error)
+	client  *http.Client
+	headers http.Header
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	refreshAt time.Time
+}
+
+// oidcIDTokenReader returns a function that reads an OIDC ID token
+// from the file or, if the file is empty, from the environment variable.
+func oidcIDTokenReader(file, envVar string) func() (string, error) {
+	return func() (string, error) {
+		var token string
+		if file != "" {
+			b, err := os.ReadFile(file)
+			if err != nil {
+				return "", fmt.Errorf("error reading OIDC token file: %v", err)
+			}
+			token = string(b)
+		} else {
+			token = os.Getenv(envVar)
+		}
+
+		token = strings.TrimSpace(token)
+		if token == "" {
+			if file != "" {
+				return "", fmt.Errorf("OIDC token file %s is empty", file)
+			}
+			return "", fmt.Errorf("OIDC token environment variable %s is not set", envVar)
+		}
+		return token, nil
+	}
+}
+
+// Token returns a valid access token, exchanging the ID token
+// for a new one when needed.
+func (s *oidcTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.token != "" && (s.refreshAt.IsZero() || now.Before(s.refreshAt)) {
+		return s.token, nil
+	}
+
+	err := s.exchange(ctx)
+	if err != nil {
+		if s.token != "" && now.Before(s.expiresAt) {
+			// The current token is still valid, try again on the next request.
+			log.Printf("[WARN] Failed to refresh the Scalr access token: %v", err)
+			return s.token, nil
+		}
+		return "", err
+	}
+
+	return s.token, nil
+}
+
+type oidcExchangeRequest struct {
+	ServiceAccountEmail string `json:"service-account-email"`
+	IDToken             string `json:"id-token"`
+}
+
+type oidcExchangeResponse struct {
+	AccessToken string `json:"access-token"`
+	// ExpiresIn is the lifetime of the access token in seconds.
+	ExpiresIn int `json:"expires-in"`
+}
+
+func (s *oidcTokenSource) exchange(ctx context.Context) error {
+	idToken, err := s.idToken()
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(oidcExchangeRequest{
+		ServiceAccountEmail: s.serviceAccountEmail,
+		IDToken:             idToken,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.exchangeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range s.headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	log.Printf("[DEBUG] Exchange OIDC token for an access token of service account %s", s.serviceAccountEmail)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error exchanging OIDC token: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkAPIResponse(resp); err != nil {
+		return fmt.Errorf("error exchanging OIDC token: %v", err)
+	}
+
+	out := &oidcExchangeResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding OIDC token exchange response: %v", err)
+	}
+	if out.AccessToken == "" {
+		return errors.New("error exchanging OIDC token: no access token in the response")
+	}
+
+	now := time.Now()
+	var expiresAt time.Time
+	if out.ExpiresIn > 0 {
+		expiresAt = now.Add(time.Duration(out.ExpiresIn) * time.Second)
+	} else if exp, ok := jwtExpiry(out.AccessToken); ok {
+		expiresAt = exp
+	}
+
+	s.token = out.AccessToken
+	s.expiresAt = expiresAt
+	s.refreshAt = time.Time{}
+	if !expiresAt.IsZero() {
+		refreshBefore := expiresAt.Sub(now) / 5
+		if refreshBefore > oidcRefreshBefore {
+			refreshBefore = oidcRefreshBefore
+		}
+		s.refreshAt = expiresAt.Add(-refreshBefore)
+	}
+
+	return nil
+}
+
+// jwtExpiry returns the expiry time from the exp claim of a JWT,
+// without verifying it.
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
+
+// oidcTransport is an http.RoundTripper that authorizes the requests
+// with the access token of the token source, replacing the token
+// the client was created with.
+type oidcTransport struct {
+	transport http.RoundTripper
+	source    *oidcTokenSource
+}
+
+func (t *oidcTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+token)
+	return t.transport.RoundTrip(r)
+}
@@ -0,0 +1,173 @@
+package scalr
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/scalr/go-scalr"
+)
+
+// testOIDCToken returns an unsigned JWT that expires at exp.
+func testOIDCToken(exp time.Time) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return strings.Join([]string{
+		encode([]byte(`{"alg":"none","typ":"JWT"}`)),
+		encode([]byte(fmt.Sprintf(`{"sub":"project_path:ci/app","exp":%d}`, exp.Unix()))),
+		"",
+	}, ".")
+}
+
+func TestOIDCTokenSource_refresh(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+	server.accessTokenTTL = time.Second
+
+	exchanges := 0
+	source := &oidcTokenSource{
+		exchangeURL:         server.Address() + "service-accounts/assume",
+		serviceAccountEmail: "ci@scalr.com",
+		idToken: func() (string, error) {
+			exchanges++
+			return testOIDCToken(time.Now().Add(time.Hour)), nil
+		},
+		client: http.DefaultClient,
+	}
+
+	token, err := source.Token(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client, err := newScalrClient(&scalr.Config{
+		Address:    server.Address(),
+		Token:      token,
+		HTTPClient: &http.Client{Transport: &oidcTransport{transport: http.DefaultTransport, source: source}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Accounts.Read(ctx, defaultAccount); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if exchanges != 1 {
+		t.Fatalf("expected 1 exchange, got %d", exchanges)
+	}
+
+	// The first token is expired by now, so it must have been refreshed.
+	time.Sleep(1100 * time.Millisecond)
+	if _, err := client.Accounts.Read(ctx, defaultAccount); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exchanges != 2 {
+		t.Fatalf("expected 2 exchanges, got %d", exchanges)
+	}
+}
+
+func TestOIDCTokenSource_invalidToken(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+
+	source := &oidcTokenSource{
+		exchangeURL:         server.Address() + "service-accounts/assume",
+		serviceAccountEmail: "ci@scalr.com",
+		idToken: func() (string, error) {
+			return testOIDCToken(time.Now().Add(-time.Minute)), nil
+		},
+		client: http.DefaultClient,
+	}
+
+	_, err := source.Token(ctx)
+	if err == nil || !strings.Contains(err.Error(), "invalid or expired OIDC token") {
+		t.Fatalf("expected exchange error, got %v", err)
+	}
+}
+
+func TestOIDCIDTokenReader(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(file, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_OIDC_TOKEN", "from-env")
+
+	if token, err := oidcIDTokenReader(file, "")(); err != nil || token != "from-file" {
+		t.Fatalf("expected token from file, got %q, %v", token, err)
+	}
+	if token, err := oidcIDTokenReader("", "TEST_OIDC_TOKEN")(); err != nil || token != "from-env" {
+		t.Fatalf("expected token from environment, got %q, %v", token, err)
+	}
+	if _, err := oidcIDTokenReader("", "TEST_OIDC_TOKEN_MISSING")(); err == nil {
+		t.Fatal("expected error for a missing environment variable")
+	}
+	if _, err := oidcIDTokenReader(filepath.Join(t.TempDir(), "missing"), "")(); err == nil {
+		t.Fatal("expected error for a missing file")
+	}
+}
+
+func TestJWTExpiry(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	if got, ok := jwtExpiry(testOIDCToken(exp)); !ok || !got.Equal(exp) {
+		t.Fatalf("expected %s, got %s", exp, got)
+	}
+	if _, ok := jwtExpiry("not-a-jwt"); ok {
+		t.Fatal("expected no expiry for an opaque token")
+	}
+}
+
+func TestProvider_oidc(t *testing.T) {
+	for _, k := range []string{
+		localTestAPIEnvVar, "TERRAFORM_CONFIG", "SCALR_HOSTNAME", "SCALR_ADDRESS", "SCALR_TOKEN", currentAccountIDEnvVar,
+	} {
+		t.Setenv(k, os.Getenv(k))
+	}
+	t.Setenv(localTestAPIEnvVar, "1")
+
+	stop, err := useLocalTestAPI()
+	if err != nil {
+		t.Fatalf("error starting local test API: %v", err)
+	}
+	defer stop()
+	// Only the OIDC token is available.
+	t.Setenv("SCALR_TOKEN", "")
+	t.Setenv("CI_OIDC_TOKEN", testOIDCToken(time.Now().Add(time.Hour)))
+
+	provider := Provider()
+	diags := provider.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"oidc": []interface{}{
+			map[string]interface{}{
+				"service_account_email": "ci@scalr.com",
+				"token_env":             "CI_OIDC_TOKEN",
+			},
+		},
+	}))
+	if diags.HasError() {
+		t.Fatalf("error configuring provider: %v", diags)
+	}
+
+	client := provider.Meta().(*scalr.Client)
+	if _, err := client.Accounts.Read(ctx, defaultAccount); err != nil {
+		t.Fatalf("error reading account with the exchanged token: %v", err)
+	}
+
+	t.Setenv("CI_OIDC_TOKEN", testOIDCToken(time.Now().Add(-time.Hour)))
+	diags = Provider().Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"oidc": []interface{}{
+			map[string]interface{}{
+				"service_account_email": "ci@scalr.com",
+				"token_env":             "CI_OIDC_TOKEN",
+			},
+		},
+	}))
+	if !diags.HasError() {
+		t.Fatal("expected an error for an expired OIDC token")
+	}
+}
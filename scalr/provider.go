@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
 
@@ -15,6 +17,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/logging"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	svchost "github.com/hashicorp/terraform-svchost"
 	"github.com/hashicorp/terraform-svchost/auth"
 	"github.com/hashicorp/terraform-svchost/disco"
@@ -28,8 +31,9 @@ var scalrServiceIDs = []string{"iacp.v3"}
 
 // Config is the structure of the configuration for the Terraform CLI.
 type Config struct {
-	Hosts       map[string]*ConfigHost            `hcl:"host"`
-	Credentials map[string]map[string]interface{} `hcl:"credentials"`
+	Hosts              map[string]*ConfigHost              `hcl:"host"`
+	Credentials        map[string]map[string]interface{}   `hcl:"credentials"`
+	CredentialsHelpers map[string]*ConfigCredentialsHelper `hcl:"credentials_helper"`
 }
 
 // ConfigHost is the structure of the "host" nested block within the CLI
@@ -39,6 +43,12 @@ type ConfigHost struct {
 	Services map[string]interface{} `hcl:"services"`
 }
 
+// ConfigCredentialsHelper is the structure of the "credentials_helper"
+// nested block within the CLI configuration.
+type ConfigCredentialsHelper struct {
+	Args []string `hcl:"args"`
+}
+
 // Provider returns a terraform.ResourceProvider.
 func Provider() *schema.Provider {
 	return &schema.Provider{
@@ -56,18 +66,80 @@ func Provider() *schema.Provider {
 				Description: "Scalr API token.",
 				DefaultFunc: schema.EnvDefaultFunc("SCALR_TOKEN", nil),
 			},
+
+			"account_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The default account ID of the resources and data sources. Takes precedence over the SCALR_ACCOUNT_ID environment variable.",
+			},
+
+			"oidc": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Authenticate with an OIDC token of a CI system, which is exchanged for a short-lived access token of a service account. Takes precedence over the token.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"service_account_email": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "Email of the service account to assume.",
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+						},
+						"token_file": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Path to the file with the OIDC token.",
+							ExactlyOneOf: []string{"oidc.0.token_file", "oidc.0.token_env"},
+						},
+						"token_env": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Name of the environment variable with the OIDC token.",
+							ExactlyOneOf: []string{"oidc.0.token_file", "oidc.0.token_env"},
+						},
+					},
+				},
+			},
+
+			"max_requests_per_second": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Description:  "Maximum number of API requests per second. Defaults to 0, which means no limit.",
+				DefaultFunc:  schema.EnvDefaultFunc("SCALR_MAX_REQUESTS_PER_SECOND", 0),
+				ValidateFunc: validation.FloatAtLeast(0),
+			},
+
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  fmt.Sprintf("Maximum number of retries of a failed API request. Defaults to %d.", defaultMaxRetries),
+				DefaultFunc:  schema.EnvDefaultFunc("SCALR_MAX_RETRIES", defaultMaxRetries),
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+
+			"lookup_cache": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Cache the lookups of environments, endpoints, webhooks and workspaces for the duration of a Terraform operation. Defaults to true.",
+				DefaultFunc: schema.EnvDefaultFunc("SCALR_LOOKUP_CACHE", true),
+			},
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
 			"scalr_access_policy":           dataSourceScalrAccessPolicy(),
 			"scalr_agent_pool":              dataSourceScalrAgentPool(),
+			"scalr_agent_pool_agents":       dataSourceScalrAgentPoolAgents(),
 			"scalr_current_account":         dataSourceScalrCurrentAccount(),
 			"scalr_current_run":             dataSourceScalrCurrentRun(),
 			"scalr_endpoint":                dataSourceScalrEndpoint(),
+			"scalr_effective_variables":     dataSourceScalrEffectiveVariables(),
 			"scalr_environment":             dataSourceScalrEnvironment(),
 			"scalr_iam_team":                dataSourceScalrIamTeam(),
 			"scalr_iam_user":                dataSourceScalrIamUser(),
 			"scalr_module_version":          dataSourceModuleVersion(),
+			"scalr_module_versions":         dataSourceScalrModuleVersions(),
+			"scalr_permissions":             dataSourceScalrPermissions(),
 			"scalr_policy_group":            dataSourceScalrPolicyGroup(),
 			"scalr_provider_configuration":  dataSourceScalrProviderConfiguration(),
 			"scalr_provider_configurations": dataSourceScalrProviderConfigurations(),
@@ -76,42 +148,54 @@ func Provider() *schema.Provider {
 			"scalr_tag":                     dataSourceScalrTag(),
 			"scalr_variable":                dataSourceScalrVariable(),
 			"scalr_variables":               dataSourceScalrVariables(),
+			"scalr_variables_from_file":     dataSourceScalrVariablesFromFile(),
 			"scalr_vcs_provider":            dataSourceScalrVcsProvider(),
 			"scalr_webhook":                 dataSourceScalrWebhook(),
 			"scalr_workspace":               dataSourceScalrWorkspace(),
 			"scalr_workspace_ids":           dataSourceScalrWorkspaceIDs(),
+			"scalr_workspace_outputs":       dataSourceScalrWorkspaceOutputs(),
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"scalr_access_policy":                  resourceScalrAccessPolicy(),
-			"scalr_account_allowed_ips":            resourceScalrAccountAllowedIps(),
-			"scalr_agent_pool":                     resourceScalrAgentPool(),
-			"scalr_agent_pool_token":               resourceScalrAgentPoolToken(),
-			"scalr_endpoint":                       resourceScalrEndpoint(),
-			"scalr_environment":                    resourceScalrEnvironment(),
-			"scalr_iam_team":                       resourceScalrIamTeam(),
-			"scalr_module":                         resourceScalrModule(),
-			"scalr_policy_group":                   resourceScalrPolicyGroup(),
-			"scalr_policy_group_linkage":           resourceScalrPolicyGroupLinkage(),
-			"scalr_provider_configuration":         resourceScalrProviderConfiguration(),
-			"scalr_provider_configuration_default": resourceScalrProviderConfigurationDefault(),
-			"scalr_role":                           resourceScalrRole(),
-			"scalr_run_trigger":                    resourceScalrRunTrigger(),
-			"scalr_service_account":                resourceScalrServiceAccount(),
-			"scalr_service_account_token":          resourceScalrServiceAccountToken(),
-			"scalr_tag":                            resourceScalrTag(),
-			"scalr_variable":                       resourceScalrVariable(),
-			"scalr_vcs_provider":                   resourceScalrVcsProvider(),
-			"scalr_webhook":                        resourceScalrWebhook(),
-			"scalr_workspace":                      resourceScalrWorkspace(),
-			"scalr_workspace_run_schedule":         resourceScalrWorkspaceRunSchedule(),
+			"scalr_access_policy":                             resourceScalrAccessPolicy(),
+			"scalr_account_allowed_ips":                       resourceScalrAccountAllowedIps(),
+			"scalr_agent_pool":                                resourceScalrAgentPool(),
+			"scalr_agent_pool_token":                          resourceScalrAgentPoolToken(),
+			"scalr_endpoint":                                  resourceScalrEndpoint(),
+			"scalr_environment":                               resourceScalrEnvironment(),
+			"scalr_iam_team":                                  resourceScalrIamTeam(),
+			"scalr_iam_team_members":                          resourceScalrIamTeamMembers(),
+			"scalr_iam_team_membership":                       resourceScalrIamTeamMembership(),
+			"scalr_module":                                    resourceScalrModule(),
+			"scalr_module_version":                            resourceScalrModuleVersion(),
+			"scalr_policy_group":                              resourceScalrPolicyGroup(),
+			"scalr_policy_group_linkage":                      resourceScalrPolicyGroupLinkage(),
+			"scalr_provider_configuration":                    resourceScalrProviderConfiguration(),
+			"scalr_provider_configuration_default":            resourceScalrProviderConfigurationDefault(),
+			"scalr_provider_configuration_workspace_defaults": resourceScalrProviderConfigurationWorkspaceDefaults(),
+			"scalr_role":                                      resourceScalrRole(),
+			"scalr_run":                                       resourceScalrRun(),
+			"scalr_run_trigger":                               resourceScalrRunTrigger(),
+			"scalr_service_account":                           resourceScalrServiceAccount(),
+			"scalr_service_account_token":                     resourceScalrServiceAccountToken(),
+			"scalr_tag":                                       resourceScalrTag(),
+			"scalr_variable":                                  resourceScalrVariable(),
+			"scalr_variable_set":                              resourceScalrVariableSet(),
+			"scalr_vcs_provider":                              resourceScalrVcsProvider(),
+			"scalr_webhook":                                   resourceScalrWebhook(),
+			"scalr_workspace":                                 resourceScalrWorkspace(),
+			"scalr_workspace_lock":                            resourceScalrWorkspaceLock(),
+			"scalr_workspace_run_schedule":                    resourceScalrWorkspaceRunSchedule(),
+			"scalr_workspace_set":                             resourceScalrWorkspaceSet(),
 		},
 
 		ConfigureContextFunc: providerConfigure,
 	}
 }
 
-func providerConfigure(_ context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+	setProviderAccountID(d.Get("account_id").(string))
+
 	// Parse the hostname for comparison,
 	hostname, err := svchost.ForComparison(d.Get("hostname").(string))
 	if err != nil {
@@ -171,9 +255,33 @@ func providerConfigure(_ context.Context, d *schema.ResourceData) (interface{},
 		return nil, diag.FromErr(discoErr)
 	}
 
+	headers := make(http.Header)
+	headers.Add("User-Agent", providerUaString)
+
+	httpClient := scalr.DefaultConfig().HTTPClient
+	var transport http.RoundTripper = logging.NewLoggingHTTPTransport(httpClient.Transport)
+
 	// Get the token from the config.
 	token := d.Get("token").(string)
 
+	// Exchange the OIDC token for an access token, which is refreshed
+	// by the transport before it expires.
+	if v, ok := d.GetOk("oidc"); ok {
+		oidc := v.([]interface{})[0].(map[string]interface{})
+		source := &oidcTokenSource{
+			exchangeURL:         oidcExchangeURL(address),
+			serviceAccountEmail: oidc["service_account_email"].(string),
+			idToken:             oidcIDTokenReader(oidc["token_file"].(string), oidc["token_env"].(string)),
+			client:              &http.Client{Transport: transport, Timeout: httpClient.Timeout},
+			headers:             headers,
+		}
+		token, err = source.Token(ctx)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		transport = &oidcTransport{transport: transport, source: source}
+	}
+
 	// Only try to get to the token from the credentials source if no token
 	// was explicitly set in the provider configuration.
 	if token == "" {
@@ -191,11 +299,12 @@ func providerConfigure(_ context.Context, d *schema.ResourceData) (interface{},
 		return nil, diag.Errorf("required token could not be found")
 	}
 
-	httpClient := scalr.DefaultConfig().HTTPClient
-	httpClient.Transport = logging.NewLoggingHTTPTransport(httpClient.Transport)
-
-	headers := make(http.Header)
-	headers.Add("User-Agent", providerUaString)
+	// Every attempt goes through the logging transport, so retries are logged too.
+	httpClient.Transport = newRetryTransport(
+		transport,
+		d.Get("max_requests_per_second").(float64),
+		d.Get("max_retries").(int),
+	)
 
 	// Create a new Scalr client config
 	cfg := &scalr.Config{
@@ -206,15 +315,30 @@ func providerConfigure(_ context.Context, d *schema.ResourceData) (interface{},
 	}
 
 	// Create a new Scalr client.
-	client, err := scalr.NewClient(cfg)
+	client, err := newScalrClient(cfg)
 	if err != nil {
 		return nil, diag.FromErr(err)
 	}
 
-	client.RetryServerErrors(true)
+	// Server errors are retried by the transport.
+	client.RetryServerErrors(false)
+
+	if d.Get("lookup_cache").(bool) {
+		enableLookupCache(client, lookupCacheTTL)
+	}
 	return client, nil
 }
 
+// oidcExchangeURL returns the URL of the endpoint that exchanges
+// OIDC tokens for access tokens.
+func oidcExchangeURL(address *url.URL) string {
+	u := *address
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return u.ResolveReference(&url.URL{Path: "service-accounts/assume"}).String()
+}
+
 // cliConfig tries to find and parse the configuration of the Terraform CLI.
 // This is an optional step, so any errors are ignored.
 func cliConfig() *Config {
@@ -253,7 +377,21 @@ func cliConfig() *Config {
 	return config
 }
 
+// credentialsSource returns the source of the credentials configured in
+// the CLI config file. The credentials helper program is asked first,
+// then the static credentials are used.
 func credentialsSource(config *Config) auth.CredentialsSource {
+	static := staticCredentialsSource(config)
+
+	helper := credentialsHelperSource(config)
+	if helper == nil {
+		return static
+	}
+
+	return auth.Credentials{helper, static}
+}
+
+func staticCredentialsSource(config *Config) auth.CredentialsSource {
 	creds := auth.NoCredentials
 
 	// Add all configured credentials to the credentials source.
@@ -274,6 +412,77 @@ func credentialsSource(config *Config) auth.CredentialsSource {
 	return creds
 }
 
+// credentialsHelperSource returns the source of the credentials helper
+// program configured in the CLI config file, or nil if there is none.
+// The program named "terraform-credentials-<name>" is looked up in the
+// plugin directories the same way Terraform does.
+func credentialsHelperSource(config *Config) auth.CredentialsSource {
+	if len(config.CredentialsHelpers) > 1 {
+		log.Printf("[ERROR] Only one credentials_helper block is allowed in the CLI config file, ignoring all of them")
+		return nil
+	}
+
+	for name, helper := range config.CredentialsHelpers {
+		path, err := credentialsHelperPath(name)
+		if err != nil {
+			log.Printf("[ERROR] Credentials helper %q is not available: %v", name, err)
+			return nil
+		}
+
+		var args []string
+		if helper != nil {
+			args = helper.Args
+		}
+		log.Printf("[DEBUG] Using credentials helper %s", path)
+		return &credentialsHelper{
+			CredentialsSource: auth.HelperProgramCredentialsSource(path, args...),
+			name:              name,
+		}
+	}
+
+	return nil
+}
+
+// credentialsHelperPath finds the executable of the credentials helper.
+func credentialsHelperPath(name string) (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+
+	filename := "terraform-credentials-" + name
+	if runtime.GOOS == "windows" {
+		filename += ".exe"
+	}
+
+	pluginsDir := filepath.Join(dir, "plugins")
+	for _, d := range []string{filepath.Join(pluginsDir, runtime.GOOS+"_"+runtime.GOARCH), pluginsDir} {
+		path := filepath.Join(d, filename)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return filepath.Abs(path)
+		}
+	}
+
+	return "", fmt.Errorf("%s not found in %s", filename, pluginsDir)
+}
+
+// credentialsHelper is the source of the credentials of a credentials
+// helper program. It gets, stores and forgets the credentials with the
+// program, and falls back to other sources if the program fails.
+type credentialsHelper struct {
+	auth.CredentialsSource
+	name string
+}
+
+func (h *credentialsHelper) ForHost(host svchost.Hostname) (auth.HostCredentials, error) {
+	creds, err := h.CredentialsSource.ForHost(host)
+	if err != nil {
+		log.Printf("[WARN] Credentials helper %q failed to get credentials for %s: %v (ignoring)", h.name, host, err)
+		return nil, nil
+	}
+	return creds, nil
+}
+
 // checkConstraints checks service version constrains against our own
 // version and returns rich and informational diagnostics in case any
 // incompatibilities are detected.
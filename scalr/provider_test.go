@@ -5,12 +5,17 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	svchost "github.com/hashicorp/terraform-svchost"
+	"github.com/hashicorp/terraform-svchost/auth"
 	"github.com/hashicorp/terraform-svchost/disco"
+	"github.com/scalr/go-scalr"
 	"github.com/scalr/terraform-provider-scalr/version"
 )
 
@@ -31,6 +36,18 @@ func init() {
 	}
 }
 
+func TestMain(m *testing.M) {
+	stop, err := useLocalTestAPI()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to start the local test API: %v\n", err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	stop()
+	os.Exit(code)
+}
+
 func TestProvider(t *testing.T) {
 	if err := Provider().InternalValidate(); err != nil {
 		t.Fatalf("err: %s", err)
@@ -120,3 +137,249 @@ func testVcsAccGithubTokenPreCheck(t *testing.T) {
 		t.Skip("Please set githubToken to run this test")
 	}
 }
+
+// testCredentialsHelper installs a fake credentials helper program, which
+// keeps the credentials in a file, into the plugin directory of a temporary
+// home directory. It returns the path of the CLI config file that uses
+// the helper and the static credentials, and the directory of the helper
+// with the credentials of every host in "<host>.json".
+func testCredentialsHelper(t *testing.T, staticCredentials string) (configFile, store string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake credentials helper is a shell script")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	pluginsDir := filepath.Join(home, ".terraform.d", "plugins")
+	if err := os.MkdirAll(pluginsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	script := `#!/bin/sh
+store="$1/$3.json"
+case "$2" in
+get)
+  if [ -f "$1/fail" ]; then
+    echo "keychain is locked" >&2
+    exit 1
+  fi
+  if [ -f "$store" ]; then cat "$store"; else echo '{}'; fi
+  ;;
+store)
+  cat > "$store"
+  ;;
+forget)
+  rm -f "$store"
+  ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(pluginsDir, "terraform-credentials-test"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	store = filepath.Join(home, "credentials")
+	if err := os.Mkdir(store, 0700); err != nil {
+		t.Fatal(err)
+	}
+	configFile = filepath.Join(home, ".terraformrc")
+	content := fmt.Sprintf("credentials_helper \"test\" {\n  args = [%q]\n}\n%s", store, staticCredentials)
+	if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TERRAFORM_CONFIG", configFile)
+
+	return configFile, store
+}
+
+func TestCredentialsSource_helper(t *testing.T) {
+	_, store := testCredentialsHelper(t, `
+credentials "static.scalr.io" {
+  token = "static-token"
+}
+`)
+
+	source := credentialsSource(cliConfig())
+	host := svchost.Hostname("example.scalr.io")
+	staticHost := svchost.Hostname("static.scalr.io")
+
+	tokenFor := func(host svchost.Hostname) string {
+		t.Helper()
+		creds, err := source.ForHost(host)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if creds == nil {
+			return ""
+		}
+		return creds.Token()
+	}
+
+	if token := tokenFor(host); token != "" {
+		t.Fatalf("expected no credentials, got %q", token)
+	}
+
+	if err := source.StoreForHost(host, auth.HostCredentialsToken("helper-token")); err != nil {
+		t.Fatalf("error storing credentials: %v", err)
+	}
+	if token := tokenFor(host); token != "helper-token" {
+		t.Fatalf("expected the token of the helper, got %q", token)
+	}
+	if token := tokenFor(staticHost); token != "static-token" {
+		t.Fatalf("expected the static token, got %q", token)
+	}
+
+	// The static credentials are used if the helper fails.
+	if err := os.WriteFile(filepath.Join(store, "fail"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if token := tokenFor(staticHost); token != "static-token" {
+		t.Fatalf("expected the static token, got %q", token)
+	}
+	if err := os.Remove(filepath.Join(store, "fail")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := source.ForgetForHost(host); err != nil {
+		t.Fatalf("error forgetting credentials: %v", err)
+	}
+	if token := tokenFor(host); token != "" {
+		t.Fatalf("expected the credentials to be forgotten, got %q", token)
+	}
+}
+
+func TestCredentialsSource_helperNotFound(t *testing.T) {
+	configFile, _ := testCredentialsHelper(t, `
+credentials "static.scalr.io" {
+  token = "static-token"
+}
+`)
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content = []byte(strings.Replace(string(content), `credentials_helper "test"`, `credentials_helper "missing"`, 1))
+	if err := os.WriteFile(configFile, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	creds, err := credentialsSource(cliConfig()).ForHost(svchost.Hostname("static.scalr.io"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds == nil || creds.Token() != "static-token" {
+		t.Fatalf("expected the static token, got %v", creds)
+	}
+}
+
+func TestProvider_credentialsHelper(t *testing.T) {
+	for _, k := range []string{
+		localTestAPIEnvVar, "TERRAFORM_CONFIG", "SCALR_HOSTNAME", "SCALR_ADDRESS", "SCALR_TOKEN", currentAccountIDEnvVar,
+	} {
+		t.Setenv(k, os.Getenv(k))
+	}
+	t.Setenv(localTestAPIEnvVar, "1")
+
+	stop, err := useLocalTestAPI()
+	if err != nil {
+		t.Fatalf("error starting local test API: %v", err)
+	}
+	defer stop()
+
+	// Keep the host configuration of the local test API.
+	hosts, err := os.ReadFile(os.Getenv("TERRAFORM_CONFIG"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, store := testCredentialsHelper(t, string(hosts))
+	content := fmt.Sprintf(`{"token":%q}`, testAPIToken)
+	if err := os.WriteFile(filepath.Join(store, os.Getenv("SCALR_HOSTNAME")+".json"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SCALR_TOKEN", "")
+
+	provider := Provider()
+	if diags := provider.Configure(context.Background(), &terraform.ResourceConfig{}); diags.HasError() {
+		t.Fatalf("error configuring provider: %v", diags)
+	}
+
+	client := provider.Meta().(*scalr.Client)
+	if _, err := client.Accounts.Read(ctx, defaultAccount); err != nil {
+		t.Fatalf("error reading account with the token of the helper: %v", err)
+	}
+}
+
+func TestProvider_accountID(t *testing.T) {
+	for _, k := range []string{
+		localTestAPIEnvVar, "TERRAFORM_CONFIG", "SCALR_HOSTNAME", "SCALR_ADDRESS", "SCALR_TOKEN", currentAccountIDEnvVar,
+	} {
+		t.Setenv(k, os.Getenv(k))
+	}
+	t.Setenv(localTestAPIEnvVar, "1")
+
+	stop, err := useLocalTestAPI()
+	if err != nil {
+		t.Fatalf("error starting local test API: %v", err)
+	}
+	defer stop()
+
+	providerAccountID.RLock()
+	saved := providerAccountID.value
+	configured := providerAccountID.configured
+	providerAccountID.RUnlock()
+	t.Cleanup(func() {
+		providerAccountID.Lock()
+		providerAccountID.value = saved
+		providerAccountID.configured = configured
+		providerAccountID.Unlock()
+	})
+
+	accountIDOf := func(raw map[string]interface{}) (string, error) {
+		r := resourceScalrEnvironment()
+		diff, err := r.Diff(ctx, nil, terraform.NewResourceConfigRaw(raw), nil)
+		if err != nil {
+			return "", err
+		}
+		return diff.Attributes["account_id"].New, nil
+	}
+	configure := func(raw map[string]interface{}) {
+		t.Helper()
+		if diags := Provider().Configure(ctx, terraform.NewResourceConfigRaw(raw)); diags.HasError() {
+			t.Fatalf("error configuring provider: %v", diags)
+		}
+	}
+
+	// The account of the provider is not known before it is configured.
+	providerAccountID.Lock()
+	providerAccountID.value, providerAccountID.configured = "", false
+	providerAccountID.Unlock()
+	t.Setenv(currentAccountIDEnvVar, "")
+	if v, err := scalrAccountIDDefaultFunc(); v != nil || err != nil {
+		t.Fatalf("expected the default to be deferred, got %v, %v", v, err)
+	}
+
+	t.Setenv(currentAccountIDEnvVar, "acc-env")
+	configure(map[string]interface{}{"account_id": "acc-provider"})
+
+	// The value of the resource takes precedence.
+	if got, err := accountIDOf(map[string]interface{}{"name": "test", "account_id": "acc-resource"}); err != nil || got != "acc-resource" {
+		t.Fatalf("expected the account of the resource, got %q, %v", got, err)
+	}
+	// Then the value of the provider.
+	if got, err := accountIDOf(map[string]interface{}{"name": "test"}); err != nil || got != "acc-provider" {
+		t.Fatalf("expected the account of the provider, got %q, %v", got, err)
+	}
+
+	// Then the environment variable.
+	configure(map[string]interface{}{})
+	if got, err := accountIDOf(map[string]interface{}{"name": "test"}); err != nil || got != "acc-env" {
+		t.Fatalf("expected the account of the environment variable, got %q, %v", got, err)
+	}
+
+	// Terraform validates the configuration with the configured provider
+	// before planning, so a missing account is reported there.
+	t.Setenv(currentAccountIDEnvVar, "")
+	diags := resourceScalrEnvironment().Validate(terraform.NewResourceConfigRaw(map[string]interface{}{"name": "test"}))
+	if !diags.HasError() || !strings.Contains(fmt.Sprint(diags), "the `account_id` argument of the provider") {
+		t.Fatalf("expected an error listing the lookup places, got %v", diags)
+	}
+}
@@ -3,23 +3,72 @@ package scalr
 import (
 	"context"
 	"errors"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"fmt"
 	"log"
+	"sort"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/scalr/go-scalr"
 )
 
+// agentPool represents an agent pool with the attributes
+// that go-scalr does not cover.
+type agentPool struct {
+	ID         string `jsonapi:"primary,agent-pools"`
+	Name       string `jsonapi:"attr,name"`
+	VcsEnabled bool   `jsonapi:"attr,vcs-enabled"`
+	IsShared   bool   `jsonapi:"attr,is-shared"`
+
+	Account              *scalr.Account       `jsonapi:"relation,account"`
+	Environment          *scalr.Environment   `jsonapi:"relation,environment"`
+	Environments         []*scalr.Environment `jsonapi:"relation,environments"`
+	ExcludedEnvironments []*scalr.Environment `jsonapi:"relation,excluded-environments"`
+}
+
+// agentPoolOptions represents the options for creating or updating
+// an agent pool, without changing the environments it is shared with.
+type agentPoolOptions struct {
+	ID         string  `jsonapi:"primary,agent-pools"`
+	Name       *string `jsonapi:"attr,name,omitempty"`
+	VcsEnabled *bool   `jsonapi:"attr,vcs-enabled,omitempty"`
+
+	Account     *scalr.Account     `jsonapi:"relation,account,omitempty"`
+	Environment *scalr.Environment `jsonapi:"relation,environment,omitempty"`
+}
+
+// agentPoolSharingOptions represents the options for creating or updating
+// an agent pool together with the environments it is shared with.
+// The relationships are always sent, so that they can be emptied.
+type agentPoolSharingOptions struct {
+	ID         string  `jsonapi:"primary,agent-pools"`
+	Name       *string `jsonapi:"attr,name,omitempty"`
+	VcsEnabled *bool   `jsonapi:"attr,vcs-enabled,omitempty"`
+	IsShared   *bool   `jsonapi:"attr,is-shared"`
+
+	Account              *scalr.Account       `jsonapi:"relation,account,omitempty"`
+	Environments         []*scalr.Environment `jsonapi:"relation,environments"`
+	ExcludedEnvironments []*scalr.Environment `jsonapi:"relation,excluded-environments"`
+}
+
 func resourceScalrAgentPool() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceScalrAgentPoolCreate,
 		ReadContext:   resourceScalrAgentPoolRead,
 		UpdateContext: resourceScalrAgentPoolUpdate,
 		DeleteContext: resourceScalrAgentPoolDelete,
+		CustomizeDiff: validateAgentPoolEnvironments,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
-		SchemaVersion: 0,
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Type:    resourceScalrAgentPoolResourceV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceScalrAgentPoolStateUpgradeV0,
+				Version: 0,
+			},
+		},
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -34,14 +83,114 @@ func resourceScalrAgentPool() *schema.Resource {
 			},
 
 			"environment_id": {
-				Type:     schema.TypeString,
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"environments", "excluded_environments"},
+				Deprecated:    "The attribute `environment_id` is deprecated. Use `environments` instead",
+			},
+			"environments": {
+				Type:     schema.TypeSet,
 				Optional: true,
-				ForceNew: true,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"excluded_environments": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"vcs_enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
 			},
 		},
 	}
 }
 
+// validateAgentPoolEnvironments checks that the pool is either shared
+// with all environments, written as `["*"]`, or with a list of them,
+// and that only a pool shared with all environments excludes some.
+func validateAgentPoolEnvironments(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if !d.NewValueKnown("environments") || !d.NewValueKnown("excluded_environments") {
+		return nil
+	}
+
+	environments := d.Get("environments").(*schema.Set)
+	shared := environments.Contains("*")
+	if shared && environments.Len() > 1 {
+		return errors.New(`environments must be either ["*"] to share the agent pool with all environments, or a list of environment IDs`)
+	}
+	if !shared && d.Get("excluded_environments").(*schema.Set).Len() > 0 {
+		return errors.New(`excluded_environments can only be set when environments is ["*"]`)
+	}
+	return nil
+}
+
+// agentPoolEnvironmentsConfigured returns true if `environments` or
+// `excluded_environments` is set in the configuration. Otherwise the
+// environments the agent pool is shared with are left to the API.
+func agentPoolEnvironmentsConfigured(d *schema.ResourceData) bool {
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() {
+		_, environments := d.GetOk("environments")
+		_, excluded := d.GetOk("excluded_environments")
+		return environments || excluded
+	}
+	return !config.GetAttr("environments").IsNull() || !config.GetAttr("excluded_environments").IsNull()
+}
+
+// expandAgentPoolEnvironments extends the options with the environments
+// the agent pool is shared with.
+func expandAgentPoolEnvironments(d *schema.ResourceData, options agentPoolOptions) *agentPoolSharingOptions {
+	sharing := &agentPoolSharingOptions{
+		Name:                 options.Name,
+		VcsEnabled:           options.VcsEnabled,
+		Account:              options.Account,
+		Environments:         make([]*scalr.Environment, 0),
+		ExcludedEnvironments: make([]*scalr.Environment, 0),
+	}
+
+	environments := d.Get("environments").(*schema.Set)
+	sharing.IsShared = scalr.Bool(environments.Contains("*"))
+	if *sharing.IsShared {
+		for _, env := range d.Get("excluded_environments").(*schema.Set).List() {
+			sharing.ExcludedEnvironments = append(sharing.ExcludedEnvironments, &scalr.Environment{ID: env.(string)})
+		}
+		return sharing
+	}
+	for _, env := range environments.List() {
+		sharing.Environments = append(sharing.Environments, &scalr.Environment{ID: env.(string)})
+	}
+	return sharing
+}
+
+// flattenAgentPoolEnvironments returns the environments the agent pool
+// is shared with, and the ones it is not shared with.
+// A pool bound to a single environment with the deprecated
+// `environment_id` is shared with that environment.
+func flattenAgentPoolEnvironments(pool *agentPool) (environments, excluded []string) {
+	environments = make([]string, 0)
+	excluded = make([]string, 0)
+	if pool.IsShared {
+		for _, env := range pool.ExcludedEnvironments {
+			excluded = append(excluded, env.ID)
+		}
+		return []string{"*"}, excluded
+	}
+
+	for _, env := range pool.Environments {
+		environments = append(environments, env.ID)
+	}
+	if pool.Environment != nil && len(environments) == 0 {
+		environments = append(environments, pool.Environment.ID)
+	}
+	sort.Strings(environments)
+	return environments, excluded
+}
+
 func resourceScalrAgentPoolCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	scalrClient := meta.(*scalr.Client)
 	var envID string
@@ -51,19 +200,25 @@ func resourceScalrAgentPoolCreate(ctx context.Context, d *schema.ResourceData, m
 	accountID := d.Get("account_id").(string)
 
 	// Create a new options struct
-	options := scalr.AgentPoolCreateOptions{
-		Name:    scalr.String(name),
-		Account: &scalr.Account{ID: accountID},
+	options := agentPoolOptions{
+		Name:       scalr.String(name),
+		VcsEnabled: scalr.Bool(d.Get("vcs_enabled").(bool)),
+		Account:    &scalr.Account{ID: accountID},
 	}
 
-	if envID, ok := d.GetOk("environment_id"); ok {
+	var payload interface{} = &options
+	if v, ok := d.GetOk("environment_id"); ok {
+		envID = v.(string)
 		options.Environment = &scalr.Environment{
-			ID: envID.(string),
+			ID: envID,
 		}
+	} else if agentPoolEnvironmentsConfigured(d) {
+		payload = expandAgentPoolEnvironments(d, options)
 	}
 
 	log.Printf("[DEBUG] Create agent pool %s for account: %s environment: %s", name, accountID, envID)
-	agentPool, err := scalrClient.AgentPools.Create(ctx, options)
+	agentPool := &agentPool{}
+	err := doAPIRequest(ctx, scalrClient, "POST", "agent-pools", payload, agentPool)
 	if err != nil {
 		return diag.Errorf(
 			"Error creating agent pool %s for account %s environment %s: %v", name, accountID, envID, err)
@@ -76,7 +231,8 @@ func resourceScalrAgentPoolRead(ctx context.Context, d *schema.ResourceData, met
 	scalrClient := meta.(*scalr.Client)
 	id := d.Id()
 	log.Printf("[DEBUG] Read configuration of agent pool: %s", id)
-	agentPool, err := scalrClient.AgentPools.Read(ctx, id)
+	agentPool := &agentPool{}
+	err := doAPIRequest(ctx, scalrClient, "GET", fmt.Sprintf("agent-pools/%s", id), nil, agentPool)
 	if err != nil {
 		if errors.Is(err, scalr.ErrResourceNotFound) {
 			log.Printf("[DEBUG] agent pool %s not found", id)
@@ -89,12 +245,17 @@ func resourceScalrAgentPoolRead(ctx context.Context, d *schema.ResourceData, met
 	// Update the config.
 	_ = d.Set("name", agentPool.Name)
 	_ = d.Set("account_id", agentPool.Account.ID)
+	_ = d.Set("vcs_enabled", agentPool.VcsEnabled)
 
 	if agentPool.Environment != nil {
 		_ = d.Set("environment_id", agentPool.Environment.ID)
 	} else {
 		_ = d.Set("environment_id", nil)
 	}
+
+	environments, excluded := flattenAgentPoolEnvironments(agentPool)
+	_ = d.Set("environments", environments)
+	_ = d.Set("excluded_environments", excluded)
 	return nil
 }
 
@@ -103,14 +264,19 @@ func resourceScalrAgentPoolUpdate(ctx context.Context, d *schema.ResourceData, m
 
 	id := d.Id()
 
-	if d.HasChange("name") {
+	if d.HasChanges("name", "vcs_enabled", "environments", "excluded_environments") {
 		// Create a new options struct
-		options := scalr.AgentPoolUpdateOptions{
-			Name: scalr.String(d.Get("name").(string)),
+		options := agentPoolOptions{
+			Name:       scalr.String(d.Get("name").(string)),
+			VcsEnabled: scalr.Bool(d.Get("vcs_enabled").(bool)),
+		}
+		var payload interface{} = &options
+		if d.HasChanges("environments", "excluded_environments") {
+			payload = expandAgentPoolEnvironments(d, options)
 		}
 
 		log.Printf("[DEBUG] Update agent pool %s", id)
-		_, err := scalrClient.AgentPools.Update(ctx, id, options)
+		err := doAPIRequest(ctx, scalrClient, "PATCH", fmt.Sprintf("agent-pools/%s", id), payload, &agentPool{})
 		if err != nil {
 			return diag.Errorf(
 				"Error updating agentPool %s: %v", id, err)
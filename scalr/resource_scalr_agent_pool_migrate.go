@@ -0,0 +1,43 @@
+package scalr
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceScalrAgentPoolResourceV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"account_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"environment_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+		},
+	}
+}
+
+// resourceScalrAgentPoolStateUpgradeV0 moves the environment the pool
+// is bound to into the environments it is shared with.
+func resourceScalrAgentPoolStateUpgradeV0(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	environments := make([]interface{}, 0)
+	if envID, ok := rawState["environment_id"].(string); ok && envID != "" {
+		environments = append(environments, envID)
+	}
+	rawState["environments"] = environments
+	rawState["excluded_environments"] = make([]interface{}, 0)
+	rawState["vcs_enabled"] = false
+	return rawState, nil
+}
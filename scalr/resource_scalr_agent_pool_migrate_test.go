@@ -0,0 +1,50 @@
+package scalr
+
+import (
+	"testing"
+)
+
+func testResourceScalrAgentPoolStateDataV0() map[string]interface{} {
+	return map[string]interface{}{
+		"id":             "apool-123",
+		"name":           "pool",
+		"account_id":     "acc-123",
+		"environment_id": "env-123",
+	}
+}
+
+func testResourceScalrAgentPoolStateDataV1() map[string]interface{} {
+	v1 := testResourceScalrAgentPoolStateDataV0()
+	v1["environments"] = []interface{}{"env-123"}
+	v1["excluded_environments"] = []interface{}{}
+	v1["vcs_enabled"] = false
+	return v1
+}
+
+func testResourceScalrAgentPoolStateDataV0AccountScope() map[string]interface{} {
+	return map[string]interface{}{
+		"id":         "apool-123",
+		"name":       "pool",
+		"account_id": "acc-123",
+	}
+}
+
+func testResourceScalrAgentPoolStateDataV1AccountScope() map[string]interface{} {
+	v1 := testResourceScalrAgentPoolStateDataV0AccountScope()
+	v1["environments"] = []interface{}{}
+	v1["excluded_environments"] = []interface{}{}
+	v1["vcs_enabled"] = false
+	return v1
+}
+
+func TestResourceScalrAgentPoolStateUpgradeV0(t *testing.T) {
+	expected := testResourceScalrAgentPoolStateDataV1()
+	actual, err := resourceScalrAgentPoolStateUpgradeV0(ctx, testResourceScalrAgentPoolStateDataV0(), nil)
+	assertCorrectState(t, err, actual, expected)
+}
+
+func TestResourceScalrAgentPoolStateUpgradeV0AccountScope(t *testing.T) {
+	expected := testResourceScalrAgentPoolStateDataV1AccountScope()
+	actual, err := resourceScalrAgentPoolStateUpgradeV0(ctx, testResourceScalrAgentPoolStateDataV0AccountScope(), nil)
+	assertCorrectState(t, err, actual, expected)
+}
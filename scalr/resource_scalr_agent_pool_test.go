@@ -1,12 +1,19 @@
 package scalr
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/scalr/go-scalr"
 )
@@ -117,6 +124,199 @@ func TestAccScalrAgentPool_import(t *testing.T) {
 	})
 }
 
+func TestAgentPool_environments(t *testing.T) {
+	client := testScalrClient(t)
+	r := resourceScalrAgentPool()
+
+	envIDs := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		env, err := client.Environments.Create(ctx, scalr.EnvironmentCreateOptions{
+			Name:    scalr.String(fmt.Sprintf("env-%d", i)),
+			Account: &scalr.Account{ID: defaultAccount},
+		})
+		if err != nil {
+			t.Fatalf("error creating environment: %v", err)
+		}
+		envIDs = append(envIDs, env.ID)
+	}
+	setOf := func(d *schema.ResourceData, key string) []string {
+		items := make([]string, 0)
+		for _, v := range d.Get(key).(*schema.Set).List() {
+			items = append(items, v.(string))
+		}
+		sort.Strings(items)
+		return items
+	}
+
+	// Shared with a list of environments.
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":         "pool",
+		"account_id":   defaultAccount,
+		"environments": []interface{}{envIDs[0], envIDs[1]},
+		"vcs_enabled":  true,
+	})
+	if diags := r.CreateContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if envs := setOf(d, "environments"); !reflect.DeepEqual(envs, []string{envIDs[0], envIDs[1]}) {
+		t.Fatalf("unexpected environments: %v", envs)
+	}
+	if !d.Get("vcs_enabled").(bool) || d.Get("environment_id").(string) != "" {
+		t.Fatalf("unexpected vcs_enabled %v or environment_id %q", d.Get("vcs_enabled"), d.Get("environment_id"))
+	}
+
+	// Shared with all environments except one.
+	id := d.Id()
+	d = schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":                  "pool",
+		"account_id":            defaultAccount,
+		"environments":          []interface{}{"*"},
+		"excluded_environments": []interface{}{envIDs[2]},
+	})
+	d.SetId(id)
+	if diags := r.UpdateContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	// The import reconciles the sets against the API.
+	d = r.Data(&terraform.InstanceState{ID: id})
+	if diags := r.ReadContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if envs := setOf(d, "environments"); !reflect.DeepEqual(envs, []string{"*"}) {
+		t.Fatalf("unexpected environments: %v", envs)
+	}
+	if excluded := setOf(d, "excluded_environments"); !reflect.DeepEqual(excluded, []string{envIDs[2]}) {
+		t.Fatalf("unexpected excluded environments: %v", excluded)
+	}
+	if d.Get("vcs_enabled").(bool) {
+		t.Fatal("expected vcs_enabled to be disabled")
+	}
+
+	// A pool bound to an environment with the deprecated environment_id
+	// is shared with that environment.
+	d = schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":           "legacy-pool",
+		"account_id":     defaultAccount,
+		"environment_id": envIDs[0],
+	})
+	if diags := r.CreateContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if envs := setOf(d, "environments"); !reflect.DeepEqual(envs, []string{envIDs[0]}) {
+		t.Fatalf("unexpected environments: %v", envs)
+	}
+	_, diff, err := testResourcePlan(t, r, d.State(), map[string]interface{}{
+		"name":         "legacy-pool",
+		"account_id":   defaultAccount,
+		"environments": []interface{}{envIDs[0]},
+	}, client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected no changes when moving to environments, got %v", diff)
+	}
+
+	for message, config := range map[string]map[string]interface{}{
+		"environments must be either": {
+			"name": "pool", "account_id": defaultAccount, "environments": []interface{}{"*", envIDs[0]},
+		},
+		"excluded_environments can only be set": {
+			"name": "pool", "account_id": defaultAccount, "environments": []interface{}{envIDs[0]}, "excluded_environments": []interface{}{envIDs[1]},
+		},
+	} {
+		_, _, err := testResourcePlan(t, r, nil, config, client)
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("expected error %q, got %v", message, err)
+		}
+	}
+}
+
+// recordingTransport keeps the bodies of the write requests.
+type recordingTransport struct {
+	bodies []string
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		t.bodies = append(t.bodies, string(body))
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestAgentPool_sharingPayload(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+	transport := &recordingTransport{}
+	client, err := newScalrClient(&scalr.Config{
+		Address:    server.Address(),
+		Token:      testAPIToken,
+		HTTPClient: &http.Client{Transport: transport},
+	})
+	if err != nil {
+		t.Fatalf("error creating Scalr client: %v", err)
+	}
+	env, err := client.Environments.Create(ctx, scalr.EnvironmentCreateOptions{
+		Name:    scalr.String("env"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating environment: %v", err)
+	}
+
+	r := resourceScalrAgentPool()
+	apply := func(state *terraform.InstanceState, config map[string]interface{}) (*terraform.InstanceState, string) {
+		state, diff, err := testResourcePlan(t, r, state, config, client)
+		if err != nil {
+			t.Fatalf("unexpected plan error: %v", err)
+		}
+		transport.bodies = nil
+		newState, diags := r.Apply(ctx, state, diff, client)
+		if diags.HasError() {
+			t.Fatalf("unexpected apply error: %v", diags)
+		}
+		if len(transport.bodies) != 1 {
+			t.Fatalf("expected 1 write request, got %d", len(transport.bodies))
+		}
+		return newState, transport.bodies[0]
+	}
+
+	// Without environments the pool is created the way it was before
+	// the sharing options.
+	_, body := apply(nil, map[string]interface{}{"name": "pool", "account_id": defaultAccount})
+	for _, field := range []string{"is-shared", "environments"} {
+		if strings.Contains(body, field) {
+			t.Fatalf("expected %s not to be sent, got %s", field, body)
+		}
+	}
+
+	// An explicitly empty list is sent.
+	_, body = apply(nil, map[string]interface{}{
+		"name":         "private-pool",
+		"account_id":   defaultAccount,
+		"environments": []interface{}{},
+	})
+	if !strings.Contains(body, `"is-shared":false`) || !strings.Contains(body, `"environments":{"data":[]}`) {
+		t.Fatalf("expected the pool not to be shared, got %s", body)
+	}
+
+	// Renaming a pool bound with the deprecated environment_id
+	// does not share it with the environment.
+	legacy := map[string]interface{}{"name": "legacy-pool", "account_id": defaultAccount, "environment_id": env.ID}
+	state, _ := apply(nil, legacy)
+	legacy["name"] = "renamed-pool"
+	_, body = apply(state, legacy)
+	if strings.Contains(body, "environments") || !strings.Contains(body, "renamed-pool") {
+		t.Fatalf("expected only the name to be sent, got %s", body)
+	}
+}
+
 func testAccCheckScalrAgentPoolExists(resId string, pool *scalr.AgentPool) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		scalrClient := testAccProvider.Meta().(*scalr.Client)
@@ -5,17 +5,19 @@ import (
 	"errors"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"log"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/scalr/go-scalr"
 )
 
 func resourceScalrAgentPoolToken() *schema.Resource {
-	return &schema.Resource{
+	r := &schema.Resource{
 		CreateContext: resourceScalrAgentPoolTokenCreate,
 		ReadContext:   resourceScalrAgentPoolTokenRead,
 		UpdateContext: resourceScalrAgentPoolTokenUpdate,
 		DeleteContext: resourceScalrAgentPoolTokenDelete,
+		CustomizeDiff: diffTokenRotation,
 		SchemaVersion: 0,
 		Schema: map[string]*schema.Schema{
 			"description": {
@@ -34,6 +36,10 @@ func resourceScalrAgentPoolToken() *schema.Resource {
 			},
 		},
 	}
+	for k, v := range tokenRotationSchema() {
+		r.Schema[k] = v
+	}
+	return r
 }
 
 func resourceScalrAgentPoolTokenCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -90,6 +96,7 @@ func resourceScalrAgentPoolTokenRead(ctx context.Context, d *schema.ResourceData
 		for _, t := range tokensList.Items {
 			if t.ID == id {
 				_ = d.Set("description", t.Description)
+				_ = d.Set("created_at", t.CreatedAt.Format(time.RFC3339))
 				return nil
 			}
 		}
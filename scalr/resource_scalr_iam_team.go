@@ -17,6 +17,7 @@ func resourceScalrIamTeam() *schema.Resource {
 		ReadContext:   resourceScalrIamTeamRead,
 		UpdateContext: resourceScalrIamTeamUpdate,
 		DeleteContext: resourceScalrIamTeamDelete,
+		CustomizeDiff: validateIamTeamUsers,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -48,10 +49,32 @@ func resourceScalrIamTeam() *schema.Resource {
 				Optional: true,
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
+			"ignore_users": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 		},
 	}
 }
 
+// teamUpdateOptions represents the options for updating a team
+// without changing its members.
+type teamUpdateOptions struct {
+	ID          string  `jsonapi:"primary,teams"`
+	Name        *string `jsonapi:"attr,name,omitempty"`
+	Description *string `jsonapi:"attr,description,omitempty"`
+}
+
+// validateIamTeamUsers checks that the users are not set on a team whose
+// members are managed outside of it.
+func validateIamTeamUsers(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Get("ignore_users").(bool) && len(d.Get("users").([]interface{})) > 0 {
+		return errors.New("users cannot be set together with ignore_users")
+	}
+	return nil
+}
+
 func parseUserDefinitions(d *schema.ResourceData) ([]*scalr.User, error) {
 	var users []*scalr.User
 
@@ -125,6 +148,12 @@ func resourceScalrIamTeamRead(ctx context.Context, d *schema.ResourceData, meta
 		_ = d.Set("account_id", t.Account.ID)
 	}
 
+	// The members are managed by scalr_iam_team_membership,
+	// scalr_iam_team_members or an identity provider.
+	if d.Get("ignore_users").(bool) {
+		return nil
+	}
+
 	var users []string
 	if len(t.Users) != 0 {
 		for _, u := range t.Users {
@@ -140,8 +169,23 @@ func resourceScalrIamTeamUpdate(ctx context.Context, d *schema.ResourceData, met
 	scalrClient := meta.(*scalr.Client)
 
 	id := d.Id()
+	ignoreUsers := d.Get("ignore_users").(bool)
+
+	// The members are left untouched when they are managed outside of the team.
+	if ignoreUsers && (d.HasChange("name") || d.HasChange("description")) {
+		opts := teamUpdateOptions{
+			Name:        scalr.String(d.Get("name").(string)),
+			Description: scalr.String(d.Get("description").(string)),
+		}
+
+		log.Printf("[DEBUG] Update team %s", id)
+		err := doAPIRequest(ctx, scalrClient, "PATCH", fmt.Sprintf("teams/%s", id), &opts, &scalr.Team{})
+		if err != nil {
+			return diag.Errorf("error updating team %s: %v", id, err)
+		}
+	}
 
-	if d.HasChange("name") || d.HasChange("description") || d.HasChange("users") {
+	if !ignoreUsers && (d.HasChange("name") || d.HasChange("description") || d.HasChange("users")) {
 
 		name := d.Get("name").(string)
 		desc := d.Get("description").(string)
@@ -0,0 +1,120 @@
+package scalr
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/scalr/go-scalr"
+)
+
+func resourceScalrIamTeamMembers() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceScalrIamTeamMembersCreate,
+		ReadContext:   resourceScalrIamTeamMembersRead,
+		UpdateContext: resourceScalrIamTeamMembersUpdate,
+		DeleteContext: resourceScalrIamTeamMembersDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"team_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"user_ids": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceScalrIamTeamMembersCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	teamID := d.Get("team_id").(string)
+	if diags := replaceTeamMembers(ctx, d, meta, teamID); diags.HasError() {
+		return diags
+	}
+
+	d.SetId(teamID)
+	return resourceScalrIamTeamMembersRead(ctx, d, meta)
+}
+
+func resourceScalrIamTeamMembersRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	id := d.Id()
+	log.Printf("[DEBUG] Read members of team %s", id)
+	team, err := scalrClient.Teams.Read(ctx, id)
+	if err != nil {
+		if errors.Is(err, scalr.ErrResourceNotFound) {
+			log.Printf("[DEBUG] Team %s not found", id)
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("error reading members of team %s: %v", id, err)
+	}
+
+	userIDs := make([]string, 0, len(team.Users))
+	for _, u := range team.Users {
+		userIDs = append(userIDs, u.ID)
+	}
+	sort.Strings(userIDs)
+
+	_ = d.Set("team_id", team.ID)
+	_ = d.Set("user_ids", userIDs)
+
+	return nil
+}
+
+func resourceScalrIamTeamMembersUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if d.HasChange("user_ids") {
+		if diags := replaceTeamMembers(ctx, d, meta, d.Id()); diags.HasError() {
+			return diags
+		}
+	}
+
+	return resourceScalrIamTeamMembersRead(ctx, d, meta)
+}
+
+func resourceScalrIamTeamMembersDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+	id := d.Id()
+
+	log.Printf("[DEBUG] Remove all members of team %s", id)
+	err := updateTeamUsers(ctx, scalrClient, id, "PATCH", nil)
+	if err != nil {
+		if errors.Is(err, scalr.ErrResourceNotFound) {
+			log.Printf("[DEBUG] Team %s not found", id)
+			return nil
+		}
+		return diag.Errorf("error removing members of team %s: %v", id, err)
+	}
+
+	return nil
+}
+
+// replaceTeamMembers makes the users of the configuration
+// the only members of the team.
+func replaceTeamMembers(ctx context.Context, d *schema.ResourceData, meta interface{}, teamID string) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	userIDs := make([]string, 0)
+	for _, v := range d.Get("user_ids").(*schema.Set).List() {
+		userIDs = append(userIDs, v.(string))
+	}
+	sort.Strings(userIDs)
+
+	log.Printf("[DEBUG] Set members of team %s: %v", teamID, userIDs)
+	err := updateTeamUsers(ctx, scalrClient, teamID, "PATCH", userIDs)
+	if err != nil {
+		return diag.Errorf("error setting members of team %s: %v", teamID, err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package scalr
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/scalr/go-scalr"
+)
+
+func TestIamTeamMembers_lifecycle(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+	client, err := newScalrClient(&scalr.Config{Address: server.Address(), Token: testAPIToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	team := testTeam(t, server, client, "user-1", "user-2", "user-3")
+	r := resourceScalrIamTeamMembers()
+
+	// The members list is authoritative.
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"team_id":  team.ID,
+		"user_ids": []interface{}{"user-3", "user-2"},
+	})
+	if diags := r.CreateContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if users := testTeamUsers(t, client, team.ID); fmt.Sprint(users) != "[user-2 user-3]" {
+		t.Fatalf("expected the members to be replaced, got %v", users)
+	}
+	state := d.State()
+
+	// A user added outside of Terraform is reported as drift.
+	if err := updateTeamUsers(ctx, client, team.ID, "POST", []string{"user-1"}); err != nil {
+		t.Fatalf("error adding user: %v", err)
+	}
+	d = r.Data(state)
+	if diags := r.ReadContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if n := d.Get("user_ids").(*schema.Set).Len(); n != 3 {
+		t.Fatalf("expected 3 members, got %d", n)
+	}
+
+	d = schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"team_id":  team.ID,
+		"user_ids": []interface{}{"user-1"},
+	})
+	d.SetId(team.ID)
+	if diags := r.UpdateContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if users := testTeamUsers(t, client, team.ID); fmt.Sprint(users) != "[user-1]" {
+		t.Fatalf("expected the members to be replaced, got %v", users)
+	}
+
+	if diags := r.DeleteContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if users := testTeamUsers(t, client, team.ID); len(users) != 0 {
+		t.Fatalf("expected no members, got %v", users)
+	}
+}
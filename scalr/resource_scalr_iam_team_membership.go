@@ -0,0 +1,165 @@
+package scalr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/scalr/go-scalr"
+)
+
+func resourceScalrIamTeamMembership() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceScalrIamTeamMembershipCreate,
+		ReadContext:   resourceScalrIamTeamMembershipRead,
+		DeleteContext: resourceScalrIamTeamMembershipDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceScalrIamTeamMembershipImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"team_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"user_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+		},
+	}
+}
+
+// updateTeamUsers adds (POST), replaces (PATCH) or removes (DELETE)
+// the users of the team, leaving the other members untouched
+// unless the users are replaced.
+func updateTeamUsers(ctx context.Context, scalrClient *scalr.Client, teamID, method string, userIDs []string) error {
+	refs := make([]map[string]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		refs = append(refs, map[string]string{"type": "users", "id": id})
+	}
+	raw, err := json.Marshal(map[string]interface{}{"data": refs})
+	if err != nil {
+		return err
+	}
+	return doAPIRequest(ctx, scalrClient, method, fmt.Sprintf("teams/%s/relationships/users", teamID), json.RawMessage(raw), nil)
+}
+
+func resourceScalrIamTeamMembershipImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	scalrClient := meta.(*scalr.Client)
+
+	id := d.Id()
+
+	teamID, userID, err := getTeamMembership(ctx, id, scalrClient)
+	if err != nil {
+		if errors.Is(err, scalr.ErrResourceNotFound) {
+			return nil, fmt.Errorf("team membership %s not found", id)
+		}
+		return nil, fmt.Errorf("error retrieving team membership %s: %v", id, err)
+	}
+
+	_ = d.Set("team_id", teamID)
+	_ = d.Set("user_id", userID)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceScalrIamTeamMembershipCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	teamID := d.Get("team_id").(string)
+	userID := d.Get("user_id").(string)
+	id := packTeamMembershipID(teamID, userID)
+
+	log.Printf("[DEBUG] Add user %s to team %s", userID, teamID)
+	err := updateTeamUsers(ctx, scalrClient, teamID, "POST", []string{userID})
+	if err != nil {
+		return diag.Errorf("error creating team membership %s: %v", id, err)
+	}
+
+	d.SetId(id)
+	return resourceScalrIamTeamMembershipRead(ctx, d, meta)
+}
+
+func resourceScalrIamTeamMembershipRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	id := d.Id()
+
+	teamID, userID, err := getTeamMembership(ctx, id, scalrClient)
+	if err != nil {
+		if errors.Is(err, scalr.ErrResourceNotFound) {
+			log.Printf("[DEBUG] Team membership %s not found", id)
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("error retrieving team membership %s: %v", id, err)
+	}
+
+	_ = d.Set("team_id", teamID)
+	_ = d.Set("user_id", userID)
+
+	return nil
+}
+
+func resourceScalrIamTeamMembershipDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	id := d.Id()
+	teamID, userID, err := unpackTeamMembershipID(id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[DEBUG] Remove user %s from team %s", userID, teamID)
+	err = updateTeamUsers(ctx, scalrClient, teamID, "DELETE", []string{userID})
+	if err != nil {
+		if errors.Is(err, scalr.ErrResourceNotFound) {
+			log.Printf("[DEBUG] Team membership %s not found", id)
+			return nil
+		}
+		return diag.Errorf("error deleting team membership %s: %v", id, err)
+	}
+
+	return nil
+}
+
+// getTeamMembership verifies that the user is a member of the team.
+func getTeamMembership(ctx context.Context, id string, scalrClient *scalr.Client) (teamID, userID string, err error) {
+	teamID, userID, err = unpackTeamMembershipID(id)
+	if err != nil {
+		return
+	}
+
+	team, err := scalrClient.Teams.Read(ctx, teamID)
+	if err != nil {
+		return
+	}
+
+	for _, u := range team.Users {
+		if u.ID == userID {
+			return
+		}
+	}
+	return "", "", scalr.ErrResourceNotFound
+}
+
+func packTeamMembershipID(teamID, userID string) string {
+	return teamID + "/" + userID
+}
+
+func unpackTeamMembershipID(id string) (teamID, userID string, err error) {
+	if s := strings.SplitN(id, "/", 2); len(s) == 2 && s[0] != "" && s[1] != "" {
+		return s[0], s[1], nil
+	}
+	return "", "", fmt.Errorf(
+		"invalid team membership ID format: %s (expected <team_id>/<user_id>)", id,
+	)
+}
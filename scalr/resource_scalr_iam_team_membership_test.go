@@ -0,0 +1,96 @@
+package scalr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/scalr/go-scalr"
+)
+
+// testTeam adds users to the test API server and creates a team
+// with the first of them as a member.
+func testTeam(t *testing.T, server *testAPIServer, client *scalr.Client, userIDs ...string) *scalr.Team {
+	t.Helper()
+	for _, id := range userIDs {
+		server.put(&testAPIResource{Type: "users", ID: id, Attributes: map[string]interface{}{"email": id + "@example.com"}})
+	}
+	team, err := client.Teams.Create(ctx, scalr.TeamCreateOptions{
+		Name:    scalr.String("test-team"),
+		Account: &scalr.Account{ID: defaultAccount},
+		Users:   []*scalr.User{{ID: userIDs[0]}},
+	})
+	if err != nil {
+		t.Fatalf("error creating team: %v", err)
+	}
+	return team
+}
+
+// testTeamUsers returns the IDs of the members of the team.
+func testTeamUsers(t *testing.T, client *scalr.Client, teamID string) []string {
+	t.Helper()
+	team, err := client.Teams.Read(ctx, teamID)
+	if err != nil {
+		t.Fatalf("error reading team: %v", err)
+	}
+	ids := make([]string, 0)
+	for _, u := range team.Users {
+		ids = append(ids, u.ID)
+	}
+	return ids
+}
+
+func TestIamTeamMembership_lifecycle(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+	client, err := newScalrClient(&scalr.Config{Address: server.Address(), Token: testAPIToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	team := testTeam(t, server, client, "user-1", "user-2")
+	r := resourceScalrIamTeamMembership()
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"team_id": team.ID,
+		"user_id": "user-2",
+	})
+	if diags := r.CreateContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if d.Id() != team.ID+"/user-2" {
+		t.Fatalf("unexpected ID %s", d.Id())
+	}
+	if users := testTeamUsers(t, client, team.ID); fmt.Sprint(users) != "[user-1 user-2]" {
+		t.Fatalf("expected the user to be added to the other members, got %v", users)
+	}
+
+	// The membership is imported by the team and user IDs.
+	imported := r.Data(&terraform.InstanceState{ID: d.Id()})
+	if _, err := r.Importer.StateContext(ctx, imported, client); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if imported.Get("team_id") != team.ID || imported.Get("user_id") != "user-2" {
+		t.Fatalf("unexpected imported state %v", imported.State())
+	}
+	_, err = r.Importer.StateContext(ctx, r.Data(&terraform.InstanceState{ID: "user-2"}), client)
+	if err == nil || !strings.Contains(err.Error(), "expected <team_id>/<user_id>") {
+		t.Fatalf("expected error for an invalid import ID, got %v", err)
+	}
+
+	if diags := r.DeleteContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if users := testTeamUsers(t, client, team.ID); fmt.Sprint(users) != "[user-1]" {
+		t.Fatalf("expected only the user to be removed, got %v", users)
+	}
+
+	// A user removed outside of Terraform is reported as drift.
+	if diags := r.ReadContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatal("expected the membership to be removed from the state")
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"log"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/scalr/go-scalr"
 )
@@ -138,6 +140,77 @@ func TestAccScalrIamTeam_import(t *testing.T) {
 	})
 }
 
+func TestIamTeam_ignoreUsers(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+	client, err := newScalrClient(&scalr.Config{Address: server.Address(), Token: testAPIToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"user-1", "user-2"} {
+		server.put(&testAPIResource{Type: "users", ID: id, Attributes: map[string]interface{}{}})
+	}
+	r := resourceScalrIamTeam()
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":                 "team",
+		"account_id":           defaultAccount,
+		"identity_provider_id": "idp-123",
+		"ignore_users":         true,
+	})
+	if diags := r.CreateContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	id := d.Id()
+	state := d.State()
+
+	// The members are managed by a membership resource.
+	membership := resourceScalrIamTeamMembership()
+	md := schema.TestResourceDataRaw(t, membership.Schema, map[string]interface{}{"team_id": id, "user_id": "user-1"})
+	if diags := membership.CreateContext(ctx, md, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	// The team sees no drift and keeps the members on update.
+	d = r.Data(state)
+	if diags := r.ReadContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	_, diff, err := testResourcePlan(t, r, d.State(), map[string]interface{}{
+		"name": "team", "account_id": defaultAccount, "identity_provider_id": "idp-123", "ignore_users": true,
+	}, client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected no changes, got %v", diff)
+	}
+
+	d = schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":                 "renamed",
+		"account_id":           defaultAccount,
+		"identity_provider_id": "idp-123",
+		"ignore_users":         true,
+	})
+	d.SetId(id)
+	if diags := r.UpdateContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if users := testTeamUsers(t, client, id); fmt.Sprint(users) != "[user-1]" {
+		t.Fatalf("expected the members to be kept, got %v", users)
+	}
+	if d.Get("name") != "renamed" {
+		t.Fatalf("expected the team to be renamed, got %v", d.Get("name"))
+	}
+
+	_, _, err = testResourcePlan(t, r, nil, map[string]interface{}{
+		"name": "team", "account_id": defaultAccount, "ignore_users": true, "users": []interface{}{"user-2"},
+	}, client)
+	if err == nil || !strings.Contains(err.Error(), "users cannot be set together with ignore_users") {
+		t.Fatalf("expected error for users with ignore_users, got %v", err)
+	}
+}
+
 func testAccCheckScalrIamTeamExists(resId string, team *scalr.Team) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		scalrClient := testAccProvider.Meta().(*scalr.Client)
@@ -0,0 +1,233 @@
+package scalr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/scalr/go-scalr"
+)
+
+// moduleVersion represents a module version with the attributes
+// that go-scalr does not cover.
+type moduleVersion struct {
+	ID           string                    `jsonapi:"primary,module-versions"`
+	Version      string                    `jsonapi:"attr,version"`
+	Status       scalr.ModuleVersionStatus `jsonapi:"attr,status"`
+	IsRootModule bool                      `jsonapi:"attr,is-root-module"`
+	Tag          string                    `jsonapi:"attr,tag"`
+	CommitSha    string                    `jsonapi:"attr,commit-sha"`
+	ErrorMessage string                    `jsonapi:"attr,error-message"`
+
+	Module *scalr.Module `jsonapi:"relation,module"`
+}
+
+// moduleVersionCreateOptions represents the options for publishing
+// a tag or a commit of the module repository as a module version.
+type moduleVersionCreateOptions struct {
+	ID        string  `jsonapi:"primary,module-versions"`
+	Version   *string `jsonapi:"attr,version,omitempty"`
+	Tag       *string `jsonapi:"attr,tag,omitempty"`
+	CommitSha *string `jsonapi:"attr,commit-sha,omitempty"`
+
+	Module *scalr.Module `jsonapi:"relation,module"`
+}
+
+func resourceScalrModuleVersion() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceScalrModuleVersionCreate,
+		ReadContext:   resourceScalrModuleVersionRead,
+		DeleteContext: resourceScalrModuleVersionDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+		},
+		CustomizeDiff: validateModuleVersionCommit,
+
+		Schema: map[string]*schema.Schema{
+			"module_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"tag": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"tag", "commit_sha"},
+			},
+			"commit_sha": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"version": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validateSemanticVersion,
+			},
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"error_message": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"is_root_module": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// validateSemanticVersion checks that the value is a semantic version,
+// e.g. 1.2.0 or 2.0.0-beta.1.
+func validateSemanticVersion(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	if _, err := version.NewSemver(v); err != nil {
+		errs = append(errs, fmt.Errorf("%s must be a semantic version, got: %s", key, v))
+	}
+	return
+}
+
+// validateModuleVersionCommit requires the version when a commit is published,
+// as it cannot be taken from the name of a tag.
+func validateModuleVersionCommit(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() != "" {
+		return nil
+	}
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() {
+		return nil
+	}
+	if !config.GetAttr("commit_sha").IsNull() && config.GetAttr("version").IsNull() {
+		return errors.New("version is required when publishing a commit")
+	}
+	return nil
+}
+
+func resourceScalrModuleVersionCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	moduleID := d.Get("module_id").(string)
+	opts := moduleVersionCreateOptions{
+		Module: &scalr.Module{ID: moduleID},
+	}
+	if tag, ok := d.GetOk("tag"); ok {
+		opts.Tag = scalr.String(tag.(string))
+	}
+	if sha, ok := d.GetOk("commit_sha"); ok {
+		opts.CommitSha = scalr.String(sha.(string))
+	}
+	if v, ok := d.GetOk("version"); ok {
+		opts.Version = scalr.String(v.(string))
+	}
+
+	log.Printf("[DEBUG] Create module version of module %s", moduleID)
+	mv := &moduleVersion{}
+	err := doAPIRequest(ctx, scalrClient, "POST", "module-versions", &opts, mv)
+	if err != nil {
+		return diag.Errorf("Error creating module version: %v", err)
+	}
+
+	d.SetId(mv.ID)
+
+	if err := waitForModuleVersion(ctx, scalrClient, mv.ID, d.Timeout(schema.TimeoutCreate)); err != nil {
+		// Keep the status and the error message in the state.
+		diags := resourceScalrModuleVersionRead(ctx, d, meta)
+		return append(diags, diag.Errorf("Error creating module version %s: %v", mv.ID, err)...)
+	}
+
+	return resourceScalrModuleVersionRead(ctx, d, meta)
+}
+
+// waitForModuleVersion polls the module version until the module is
+// uploaded and parsed. It fails if the module version ends up errored.
+func waitForModuleVersion(ctx context.Context, scalrClient *scalr.Client, id string, timeout time.Duration) error {
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{string(scalr.ModuleVersionNotUploaded), string(scalr.ModuleVersionPending)},
+		Target:  []string{string(scalr.ModuleVersionOk), string(scalr.ModuleVersionErrored)},
+		Timeout: timeout,
+		Refresh: func() (interface{}, string, error) {
+			mv := &moduleVersion{}
+			err := doAPIRequest(ctx, scalrClient, "GET", fmt.Sprintf("module-versions/%s", id), nil, mv)
+			if err != nil {
+				return nil, "", err
+			}
+			log.Printf("[DEBUG] Module version %s status: %s", id, mv.Status)
+			return mv, string(mv.Status), nil
+		},
+	}
+
+	log.Printf("[DEBUG] Wait for module version %s to be processed", id)
+	v, err := stateConf.WaitForStateContext(ctx)
+	if err != nil {
+		return fmt.Errorf("error waiting for module version to be processed: %v", err)
+	}
+
+	mv := v.(*moduleVersion)
+	if mv.Status == scalr.ModuleVersionErrored {
+		return fmt.Errorf("module version is errored: %s", mv.ErrorMessage)
+	}
+
+	return nil
+}
+
+func resourceScalrModuleVersionRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+	id := d.Id()
+
+	log.Printf("[DEBUG] Read configuration of module version: %s", id)
+	mv := &moduleVersion{}
+	err := doAPIRequest(ctx, scalrClient, "GET", fmt.Sprintf("module-versions/%s", id), nil, mv)
+	if err != nil {
+		if errors.Is(err, scalr.ErrResourceNotFound) {
+			log.Printf("[DEBUG] Module version %s no longer exists", id)
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("Error reading configuration of module version %s: %v", id, err)
+	}
+
+	if mv.Module != nil {
+		_ = d.Set("module_id", mv.Module.ID)
+	}
+	_ = d.Set("tag", mv.Tag)
+	_ = d.Set("commit_sha", mv.CommitSha)
+	_ = d.Set("version", mv.Version)
+	_ = d.Set("status", mv.Status)
+	_ = d.Set("error_message", mv.ErrorMessage)
+	_ = d.Set("is_root_module", mv.IsRootModule)
+
+	return nil
+}
+
+func resourceScalrModuleVersionDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+	id := d.Id()
+
+	log.Printf("[DEBUG] Delete module version %s", id)
+	err := doAPIRequest(ctx, scalrClient, "DELETE", fmt.Sprintf("module-versions/%s", id), nil, nil)
+	if err != nil {
+		if errors.Is(err, scalr.ErrResourceNotFound) {
+			return nil
+		}
+		return diag.Errorf("Error deleting module version %s: %v", id, err)
+	}
+
+	return nil
+}
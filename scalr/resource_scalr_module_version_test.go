@@ -0,0 +1,204 @@
+package scalr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/scalr/go-scalr"
+)
+
+func TestAccScalrModuleVersion_basic(t *testing.T) {
+	rInt := GetRandomInteger()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			//TODO:ape delete skip after SCALRCORE-19891
+			t.Skip("Working on personal token but not working with github action token.")
+			testVcsAccGithubTokenPreCheck(t)
+		},
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckScalrModuleVersionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccScalrModuleVersionConfig(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("scalr_module_version.test", "module_id", "scalr_module.test", "id"),
+					resource.TestCheckResourceAttr("scalr_module_version.test", "tag", "v0.0.1"),
+					resource.TestCheckResourceAttr("scalr_module_version.test", "version", "0.0.1"),
+					resource.TestCheckResourceAttr("scalr_module_version.test", "status", string(scalr.ModuleVersionOk)),
+					resource.TestCheckResourceAttr("scalr_module_version.test", "error_message", ""),
+				),
+			},
+			{
+				ResourceName:      "scalr_module_version.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccScalrModuleVersion_validation(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource scalr_module_version test {
+  module_id = "mod-123"
+}`,
+				ExpectError: regexp.MustCompile("one of `commit_sha,tag` must be specified"),
+			},
+			{
+				Config: `
+resource scalr_module_version test {
+  module_id  = "mod-123"
+  commit_sha = "5d1c3f9"
+}`,
+				ExpectError: regexp.MustCompile("version is required when publishing a commit"),
+			},
+			{
+				Config: `
+resource scalr_module_version test {
+  module_id  = "mod-123"
+  commit_sha = "5d1c3f9"
+  version    = "latest"
+}`,
+				ExpectError: regexp.MustCompile("version must be a semantic version"),
+			},
+		},
+	})
+}
+
+// testModule creates a module in the local test API.
+func testModule(t *testing.T, client *scalr.Client, tagPrefix string) *scalr.Module {
+	t.Helper()
+	module, err := client.Modules.Create(ctx, scalr.ModuleCreateOptions{
+		Account: &scalr.Account{ID: defaultAccount},
+		VCSRepo: &scalr.ModuleVCSRepo{
+			Identifier: "Scalr/terraform-scalr-modules",
+			TagPrefix:  scalr.String(tagPrefix),
+		},
+		VcsProvider: &scalr.VcsProvider{ID: "vcs-123"},
+	})
+	if err != nil {
+		t.Fatalf("error creating module: %v", err)
+	}
+	return module
+}
+
+func TestModuleVersion_publish(t *testing.T) {
+	client := testScalrClient(t)
+	module := testModule(t, client, "network/")
+	r := resourceScalrModuleVersion()
+
+	t.Run("tag", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+			"module_id": module.ID,
+			"tag":       "network/v2.1.0",
+		})
+		if diags := r.CreateContext(ctx, d, client); diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+		if v := d.Get("version").(string); v != "2.1.0" {
+			t.Fatalf("expected the version to be taken from the tag, got %q", v)
+		}
+		if status := d.Get("status").(string); status != string(scalr.ModuleVersionOk) {
+			t.Fatalf("expected status %q, got %q", scalr.ModuleVersionOk, status)
+		}
+		if !d.Get("is_root_module").(bool) {
+			t.Fatal("expected a root module")
+		}
+
+		// The same version cannot be published twice.
+		d = schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+			"module_id":  module.ID,
+			"commit_sha": "5d1c3f9",
+			"version":    "2.1.0",
+		})
+		if diags := r.CreateContext(ctx, d, client); !diags.HasError() {
+			t.Fatal("expected an error for a duplicate version")
+		}
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+			"module_id":  module.ID,
+			"commit_sha": "5d1c3f9",
+			"version":    "2.2.0-rc.1",
+		})
+		if diags := r.CreateContext(ctx, d, client); diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+		if v := d.Get("commit_sha").(string); v != "5d1c3f9" {
+			t.Fatalf("expected commit 5d1c3f9, got %q", v)
+		}
+
+		if diags := r.DeleteContext(ctx, d, client); diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+		if diags := r.ReadContext(ctx, d, client); diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+		if d.Id() != "" {
+			t.Fatal("expected the deleted module version to be removed from the state")
+		}
+	})
+
+	t.Run("errored", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+			"module_id": module.ID,
+			"tag":       "network/v3.0.0-invalid",
+		})
+		diags := r.CreateContext(ctx, d, client)
+		if !diags.HasError() {
+			t.Fatal("expected an error for the errored module version")
+		}
+		if msg := fmt.Sprint(diags); !strings.Contains(msg, "Failed to parse module") {
+			t.Fatalf("expected the parse error of the module version, got %s", msg)
+		}
+		if d.Id() == "" {
+			t.Fatal("expected the errored module version to be kept in the state")
+		}
+		if status := d.Get("status").(string); status != string(scalr.ModuleVersionErrored) {
+			t.Fatalf("expected status %q, got %q", scalr.ModuleVersionErrored, status)
+		}
+	})
+}
+
+func testAccCheckScalrModuleVersionDestroy(s *terraform.State) error {
+	scalrClient := testAccProvider.Meta().(*scalr.Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "scalr_module_version" {
+			continue
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No instance ID is set")
+		}
+
+		_, err := scalrClient.ModuleVersions.Read(ctx, rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("Module version %s still exists", rs.Primary.ID)
+		}
+	}
+
+	return nil
+}
+
+func testAccScalrModuleVersionConfig(rInt int) string {
+	return fmt.Sprintf(`
+%s
+
+resource scalr_module_version test {
+  module_id = scalr_module.test.id
+  tag       = "v0.0.1"
+}`, testAccScalrAccountModule(rInt))
+}
@@ -3,10 +3,14 @@ package scalr
 import (
 	"context"
 	"errors"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"fmt"
 	"log"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/scalr/go-scalr"
 )
 
@@ -19,6 +23,10 @@ func resourceScalrPolicyGroup() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -97,8 +105,151 @@ func resourceScalrPolicyGroup() *schema.Resource {
 				Computed: true,
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
+			"policy_override": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+						},
+						"enabled": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Computed: true,
+						},
+						"enforced_level": {
+							Type:     schema.TypeString,
+							Optional: true,
+							ValidateFunc: validation.StringInSlice(
+								[]string{
+									scalr.PolicyEnforcementLevelHard,
+									scalr.PolicyEnforcementLevelSoft,
+									scalr.PolicyEnforcementLevelAdvisory,
+								},
+								false,
+							),
+						},
+					},
+				},
+				Set: policyOverrideHash,
+			},
 		},
+		CustomizeDiff: validatePolicyOverrides,
+	}
+}
+
+// policyUpdateOptions represents the options for updating a policy
+// of a policy group, which go-scalr does not cover.
+type policyUpdateOptions struct {
+	ID               string                        `jsonapi:"primary,policies"`
+	Enabled          *bool                         `jsonapi:"attr,enabled,omitempty"`
+	EnforcementLevel *scalr.PolicyEnforcementLevel `jsonapi:"attr,enforced-level,omitempty"`
+}
+
+// policyOverrideHash identifies the overrides by the name of the policy,
+// so that an `enabled` flag that is not configured, and thus read back from
+// the policy, does not change the identity of the override.
+func policyOverrideHash(v interface{}) int {
+	return schema.HashString(v.(map[string]interface{})["name"].(string))
+}
+
+// policyOverridesWithEnabled returns the names of the overridden policies
+// that have `enabled` set in the configuration. The others keep the flag
+// from the VCS repository. Without a raw configuration all the overrides
+// are considered to set it.
+func policyOverridesWithEnabled(d *schema.ResourceData) map[string]bool {
+	names := make(map[string]bool)
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() {
+		for _, v := range d.Get("policy_override").(*schema.Set).List() {
+			names[v.(map[string]interface{})["name"].(string)] = true
+		}
+		return names
+	}
+	overrides := config.GetAttr("policy_override")
+	if overrides.IsNull() || !overrides.IsKnown() {
+		return names
+	}
+	for it := overrides.ElementIterator(); it.Next(); {
+		_, override := it.Element()
+		if override.IsNull() || !override.IsKnown() {
+			continue
+		}
+		name := override.GetAttr("name")
+		if name.IsNull() || !name.IsKnown() || override.GetAttr("enabled").IsNull() {
+			continue
+		}
+		names[name.AsString()] = true
+	}
+	return names
+}
+
+// validatePolicyOverrides rejects several overrides of the same policy.
+func validatePolicyOverrides(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	seen := make(map[string]bool)
+	for _, v := range d.Get("policy_override").(*schema.Set).List() {
+		name := v.(map[string]interface{})["name"].(string)
+		if name == "" {
+			continue
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate policy_override for policy %q", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// applyPolicyOverrides sets the enabled flag and the enforcement level of
+// the policies of the policy group that differ from the overrides.
+// The enabled flag is only set for the policies listed in withEnabled.
+// It is done after every sync of the VCS repository, which resets them.
+func applyPolicyOverrides(
+	ctx context.Context, scalrClient *scalr.Client, id string, overrides []interface{}, withEnabled map[string]bool,
+) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	pg, err := scalrClient.PolicyGroups.Read(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error reading policies: %v", err)
+	}
+	policies := make(map[string]*scalr.Policy)
+	for _, policy := range pg.Policies {
+		policies[policy.Name] = policy
+	}
+
+	for _, v := range overrides {
+		override := v.(map[string]interface{})
+		name := override["name"].(string)
+		policy, ok := policies[name]
+		if !ok {
+			return fmt.Errorf("policy %q not found in the policy group", name)
+		}
+
+		opts := policyUpdateOptions{ID: policy.ID}
+		if enabled := override["enabled"].(bool); withEnabled[name] && enabled != policy.Enabled {
+			opts.Enabled = scalr.Bool(enabled)
+		}
+		if level := scalr.PolicyEnforcementLevel(override["enforced_level"].(string)); level != "" && level != policy.EnforcementLevel {
+			opts.EnforcementLevel = &level
+		}
+		if opts.Enabled == nil && opts.EnforcementLevel == nil {
+			continue
+		}
+
+		log.Printf("[DEBUG] Override policy %s (%s) of policy group %s", name, policy.ID, id)
+		err := doAPIRequest(ctx, scalrClient, "PATCH", fmt.Sprintf("policies/%s", policy.ID), &opts, nil)
+		if err != nil {
+			return fmt.Errorf("error overriding policy %q: %v", name, err)
+		}
 	}
+
+	return nil
 }
 
 func resourceScalrPolicyGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -138,9 +289,54 @@ func resourceScalrPolicyGroupCreate(ctx context.Context, d *schema.ResourceData,
 	}
 
 	d.SetId(pg.ID)
+
+	if err := waitForPolicyGroup(ctx, scalrClient, pg.ID, d.Timeout(schema.TimeoutCreate)); err != nil {
+		// Keep the status and the error message in the state.
+		diags := resourceScalrPolicyGroupRead(ctx, d, meta)
+		return append(diags, diag.Errorf("error creating policy group %s: %v", pg.ID, err)...)
+	}
+
+	if err := applyPolicyOverrides(
+		ctx, scalrClient, pg.ID, d.Get("policy_override").(*schema.Set).List(), policyOverridesWithEnabled(d),
+	); err != nil {
+		diags := resourceScalrPolicyGroupRead(ctx, d, meta)
+		return append(diags, diag.Errorf("error creating policy group %s: %v", pg.ID, err)...)
+	}
+
 	return resourceScalrPolicyGroupRead(ctx, d, meta)
 }
 
+// waitForPolicyGroup polls the policy group until its policies are fetched
+// from the VCS repository. It fails if the policy group ends up errored.
+func waitForPolicyGroup(ctx context.Context, scalrClient *scalr.Client, id string, timeout time.Duration) error {
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{string(scalr.PolicyGroupStatusFetching)},
+		Target:  []string{string(scalr.PolicyGroupStatusActive), string(scalr.PolicyGroupStatusErrored)},
+		Timeout: timeout,
+		Refresh: func() (interface{}, string, error) {
+			pg, err := scalrClient.PolicyGroups.Read(ctx, id)
+			if err != nil {
+				return nil, "", err
+			}
+			log.Printf("[DEBUG] Policy group %s status: %s", id, pg.Status)
+			return pg, string(pg.Status), nil
+		},
+	}
+
+	log.Printf("[DEBUG] Wait for policy group %s to fetch policies", id)
+	v, err := stateConf.WaitForStateContext(ctx)
+	if err != nil {
+		return fmt.Errorf("error waiting for policies to be fetched: %v", err)
+	}
+
+	pg := v.(*scalr.PolicyGroup)
+	if pg.Status == scalr.PolicyGroupStatusErrored {
+		return fmt.Errorf("policy group is errored: %s", pg.ErrorMessage)
+	}
+
+	return nil
+}
+
 func resourceScalrPolicyGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	scalrClient := meta.(*scalr.Client)
 
@@ -181,6 +377,44 @@ func resourceScalrPolicyGroupRead(ctx context.Context, d *schema.ResourceData, m
 	}
 	_ = d.Set("policies", policies)
 
+	// Refresh the overrides from the policies, so that the changes made
+	// outside of Terraform or by a sync of the VCS repository are reported.
+	// The override of a missing policy is kept, so that it stays visible.
+	var diags diag.Diagnostics
+	if v, ok := d.GetOk("policy_override"); ok {
+		policies := make(map[string]*scalr.Policy)
+		for _, policy := range pg.Policies {
+			policies[policy.Name] = policy
+		}
+
+		overrides := make([]interface{}, 0)
+		for _, v := range v.(*schema.Set).List() {
+			override := v.(map[string]interface{})
+			name := override["name"].(string)
+			policy, ok := policies[name]
+			if !ok {
+				overrides = append(overrides, override)
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Warning,
+					Summary:  fmt.Sprintf("Policy %q of policy_override is not found in policy group %s", name, id),
+					Detail: "The policy may have been renamed or removed from the VCS repository. " +
+						"Fix the name of the policy or remove the override.",
+				})
+				continue
+			}
+			level := ""
+			if override["enforced_level"].(string) != "" {
+				level = string(policy.EnforcementLevel)
+			}
+			overrides = append(overrides, map[string]interface{}{
+				"name":           policy.Name,
+				"enabled":        policy.Enabled,
+				"enforced_level": level,
+			})
+		}
+		_ = d.Set("policy_override", overrides)
+	}
+
 	var envs []string
 	if len(pg.Environments) != 0 {
 		for _, env := range pg.Environments {
@@ -189,7 +423,7 @@ func resourceScalrPolicyGroupRead(ctx context.Context, d *schema.ResourceData, m
 	}
 	_ = d.Set("environments", envs)
 
-	return nil
+	return diags
 }
 
 func resourceScalrPolicyGroupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -228,6 +462,18 @@ func resourceScalrPolicyGroupUpdate(ctx context.Context, d *schema.ResourceData,
 		if err != nil {
 			return diag.Errorf("error updating policy group %s: %v", id, err)
 		}
+
+		if err := waitForPolicyGroup(ctx, scalrClient, id, d.Timeout(schema.TimeoutUpdate)); err != nil {
+			diags := resourceScalrPolicyGroupRead(ctx, d, meta)
+			return append(diags, diag.Errorf("error updating policy group %s: %v", id, err)...)
+		}
+	}
+
+	if err := applyPolicyOverrides(
+		ctx, scalrClient, id, d.Get("policy_override").(*schema.Set).List(), policyOverridesWithEnabled(d),
+	); err != nil {
+		diags := resourceScalrPolicyGroupRead(ctx, d, meta)
+		return append(diags, diag.Errorf("error updating policy group %s: %v", id, err)...)
 	}
 
 	return resourceScalrPolicyGroupRead(ctx, d, meta)
@@ -3,8 +3,10 @@ package scalr
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/scalr/go-scalr"
 )
@@ -47,6 +49,55 @@ func TestAccPolicyGroupLinkage_basic(t *testing.T) {
 	})
 }
 
+func TestPolicyGroupLinkage_lookupCache(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+	client, err := newScalrClient(&scalr.Config{Address: server.Address(), Token: testAPIToken})
+	if err != nil {
+		t.Fatalf("error creating Scalr client: %v", err)
+	}
+	enableLookupCache(client, time.Minute)
+
+	server.put(&testAPIResource{
+		Type:       "policy-groups",
+		ID:         "pgrp-123",
+		Attributes: map[string]interface{}{"name": "test", "status": "active"},
+	})
+
+	// The environment and the linkage are created in a single apply,
+	// so the environment read by its resource is cached before the linkage.
+	env := resourceScalrEnvironment()
+	envData := schema.TestResourceDataRaw(t, env.Schema, map[string]interface{}{
+		"name":       "test-env",
+		"account_id": defaultAccount,
+	})
+	if diags := env.CreateContext(ctx, envData, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	r := resourceScalrPolicyGroupLinkage()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"policy_group_id": "pgrp-123",
+		"environment_id":  envData.Id(),
+	})
+	if diags := r.CreateContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if d.Id() == "" {
+		t.Fatal("expected the linkage to be found after it was created")
+	}
+
+	if diags := r.DeleteContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if diags := r.ReadContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatal("expected the linkage to be gone after it was deleted")
+	}
+}
+
 func TestAccPolicyGroupLinkage_import(t *testing.T) {
 	rInt := GetRandomInteger()
 
@@ -2,11 +2,15 @@ package scalr
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/scalr/go-scalr"
 )
@@ -37,7 +41,11 @@ func TestAccPolicyGroup_basic(t *testing.T) {
 						"name",
 						fmt.Sprintf("test-pg-%d", rInt),
 					),
-					resource.TestCheckResourceAttrSet("scalr_policy_group.test", "status"),
+					resource.TestCheckResourceAttr(
+						"scalr_policy_group.test",
+						"status",
+						string(scalr.PolicyGroupStatusActive),
+					),
 					resource.TestCheckResourceAttr(
 						"scalr_policy_group.test",
 						"error_message",
@@ -275,6 +283,231 @@ func TestAccPolicyGroup_import(t *testing.T) {
 	})
 }
 
+func TestPolicyGroup_waitForFetch(t *testing.T) {
+	client := testScalrClient(t)
+	r := resourceScalrPolicyGroup()
+
+	newResourceData := func(identifier string) *schema.ResourceData {
+		return schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+			"name":            "test-pg",
+			"account_id":      defaultAccount,
+			"vcs_provider_id": "vcs-123",
+			"vcs_repo": []interface{}{
+				map[string]interface{}{"identifier": identifier},
+			},
+		})
+	}
+
+	t.Run("active", func(t *testing.T) {
+		d := newResourceData("Scalr/policies")
+		if diags := r.CreateContext(ctx, d, client); diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+		if status := d.Get("status").(string); status != string(scalr.PolicyGroupStatusActive) {
+			t.Fatalf("expected status %q, got %q", scalr.PolicyGroupStatusActive, status)
+		}
+		if n := len(d.Get("policies").([]interface{})); n != 2 {
+			t.Fatalf("expected 2 policies, got %d", n)
+		}
+
+		// Changing the repository fetches the policies again.
+		state := d.State()
+		d = newResourceData("Scalr/invalid-policies")
+		d.SetId(state.ID)
+		if diags := r.UpdateContext(ctx, d, client); !diags.HasError() {
+			t.Fatal("expected an error for the errored policy group")
+		} else if msg := fmt.Sprint(diags); !strings.Contains(msg, "Repository Scalr/invalid-policies not found") {
+			t.Fatalf("expected the error message of the policy group, got %s", msg)
+		}
+	})
+
+	t.Run("errored", func(t *testing.T) {
+		d := newResourceData("Scalr/invalid-policies")
+		diags := r.CreateContext(ctx, d, client)
+		if !diags.HasError() {
+			t.Fatal("expected an error for the errored policy group")
+		}
+		if msg := fmt.Sprint(diags); !strings.Contains(msg, "Repository Scalr/invalid-policies not found") {
+			t.Fatalf("expected the error message of the policy group, got %s", msg)
+		}
+		if d.Id() == "" {
+			t.Fatal("expected the errored policy group to be kept in the state")
+		}
+		if status := d.Get("status").(string); status != string(scalr.PolicyGroupStatusErrored) {
+			t.Fatalf("expected status %q, got %q", scalr.PolicyGroupStatusErrored, status)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		pg, err := client.PolicyGroups.Create(ctx, scalr.PolicyGroupCreateOptions{
+			Name:        scalr.String("test-pg"),
+			VCSRepo:     &scalr.PolicyGroupVCSRepoOptions{Identifier: scalr.String("Scalr/policies")},
+			Account:     &scalr.Account{ID: defaultAccount},
+			VcsProvider: &scalr.VcsProvider{ID: "vcs-123"},
+		})
+		if err != nil {
+			t.Fatalf("error creating policy group: %v", err)
+		}
+		err = waitForPolicyGroup(ctx, client, pg.ID, time.Millisecond)
+		if err == nil || !strings.Contains(err.Error(), "timeout") {
+			t.Fatalf("expected a timeout error, got %v", err)
+		}
+	})
+}
+
+func TestPolicyGroup_policyOverride(t *testing.T) {
+	client := testScalrClient(t)
+	r := resourceScalrPolicyGroup()
+
+	newResourceData := func(identifier string, overrides ...interface{}) *schema.ResourceData {
+		return schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+			"name":            "test-pg",
+			"account_id":      defaultAccount,
+			"vcs_provider_id": "vcs-123",
+			"vcs_repo": []interface{}{
+				map[string]interface{}{"identifier": identifier},
+			},
+			"policy_override": overrides,
+		})
+	}
+	policyOf := func(d *schema.ResourceData, name string) map[string]interface{} {
+		for _, v := range d.Get("policies").([]interface{}) {
+			if policy := v.(map[string]interface{}); policy["name"] == name {
+				return policy
+			}
+		}
+		t.Fatalf("policy %s not found", name)
+		return nil
+	}
+	override := map[string]interface{}{
+		"name":           "instance_types",
+		"enabled":        false,
+		"enforced_level": scalr.PolicyEnforcementLevelAdvisory,
+	}
+
+	d := newResourceData("Scalr/policies", override)
+	if diags := r.CreateContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	policy := policyOf(d, "instance_types")
+	if policy["enabled"] != false || policy["enforced_level"] != scalr.PolicyEnforcementLevelAdvisory {
+		t.Fatalf("expected the policy to be overridden, got %v", policy)
+	}
+	if policy := policyOf(d, "workspace_destroy"); policy["enforced_level"] != scalr.PolicyEnforcementLevelHard {
+		t.Fatalf("expected the policy without an override to be kept, got %v", policy)
+	}
+
+	// A change made outside of Terraform is reported as drift.
+	pg, err := client.PolicyGroups.Read(ctx, d.Id())
+	if err != nil {
+		t.Fatalf("error reading policy group: %v", err)
+	}
+	for _, p := range pg.Policies {
+		if p.Name != "instance_types" {
+			continue
+		}
+		level := scalr.PolicyEnforcementLevel(scalr.PolicyEnforcementLevelHard)
+		err := doAPIRequest(ctx, client, "PATCH", "policies/"+p.ID, &policyUpdateOptions{
+			ID: p.ID, EnforcementLevel: &level,
+		}, nil)
+		if err != nil {
+			t.Fatalf("error updating policy: %v", err)
+		}
+	}
+	if diags := r.ReadContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	overrides := d.Get("policy_override").(*schema.Set).List()
+	if len(overrides) != 1 || overrides[0].(map[string]interface{})["enforced_level"] != scalr.PolicyEnforcementLevelHard {
+		t.Fatalf("expected the drift to be reported, got %v", overrides)
+	}
+
+	// The overrides survive a sync of the VCS repository.
+	id := d.Id()
+	d = newResourceData("Scalr/other-policies", override)
+	d.SetId(id)
+	if diags := r.UpdateContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	policy = policyOf(d, "instance_types")
+	if policy["enabled"] != false || policy["enforced_level"] != scalr.PolicyEnforcementLevelAdvisory {
+		t.Fatalf("expected the override to be applied after the sync, got %v", policy)
+	}
+
+	policyIDs := make(map[string]string)
+	pg, err = client.PolicyGroups.Read(ctx, id)
+	if err != nil {
+		t.Fatalf("error reading policy group: %v", err)
+	}
+	for _, p := range pg.Policies {
+		policyIDs[p.Name] = p.ID
+	}
+
+	// An override without enabled keeps the flag of the policy.
+	config := map[string]interface{}{
+		"name":            "test-pg",
+		"account_id":      defaultAccount,
+		"vcs_provider_id": "vcs-123",
+		"vcs_repo": []interface{}{
+			map[string]interface{}{"identifier": "Scalr/other-policies"},
+		},
+		"policy_override": []interface{}{
+			override,
+			map[string]interface{}{"name": "workspace_destroy", "enforced_level": scalr.PolicyEnforcementLevelSoft},
+		},
+	}
+	state, diff, err := testResourcePlan(t, r, d.State(), config, client)
+	if err != nil {
+		t.Fatalf("unexpected plan error: %v", err)
+	}
+	state, diags := r.Apply(ctx, state, diff, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected apply error: %v", diags)
+	}
+	d = r.Data(state)
+	policy = policyOf(d, "workspace_destroy")
+	if policy["enabled"] != true || policy["enforced_level"] != scalr.PolicyEnforcementLevelSoft {
+		t.Fatalf("expected only the enforcement level to be overridden, got %v", policy)
+	}
+
+	// Nor does it re-enable a policy disabled in the repository.
+	err = doAPIRequest(ctx, client, "PATCH", "policies/"+policyIDs["workspace_destroy"], &policyUpdateOptions{
+		ID: policyIDs["workspace_destroy"], Enabled: scalr.Bool(false),
+	}, nil)
+	if err != nil {
+		t.Fatalf("error updating policy: %v", err)
+	}
+	if diags := r.ReadContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if _, diff, err := testResourcePlan(t, r, d.State(), config, client); err != nil || !diff.Empty() {
+		t.Fatalf("expected no changes, got %v, %v", diff, err)
+	}
+
+	// The override of a missing policy is kept with a warning.
+	err = doAPIRequest(ctx, client, "PATCH", "policies/"+policyIDs["instance_types"], json.RawMessage(fmt.Sprintf(
+		`{"data":{"type":"policies","id":%q,"attributes":{"name":"renamed"}}}`, policyIDs["instance_types"],
+	)), nil)
+	if err != nil {
+		t.Fatalf("error renaming policy: %v", err)
+	}
+	diags = r.ReadContext(ctx, d, client)
+	if diags.HasError() || len(diags) != 1 || !strings.Contains(diags[0].Summary, `Policy "instance_types"`) {
+		t.Fatalf("expected a warning for the missing policy, got %v", diags)
+	}
+	if n := d.Get("policy_override").(*schema.Set).Len(); n != 2 {
+		t.Fatalf("expected the overrides to be kept, got %d", n)
+	}
+
+	// Overriding a missing policy fails.
+	d = newResourceData("Scalr/other-policies", map[string]interface{}{"name": "missing", "enabled": false})
+	d.SetId(id)
+	diags = r.UpdateContext(ctx, d, client)
+	if !diags.HasError() || !strings.Contains(fmt.Sprint(diags), `policy "missing" not found`) {
+		t.Fatalf("expected an error for a missing policy, got %v", diags)
+	}
+}
+
 func testAccCheckPolicyGroupExists(resID string, policyGroup *scalr.PolicyGroup) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		scalrClient := testAccProvider.Meta().(*scalr.Client)
@@ -88,9 +88,11 @@ func resourceScalrProviderConfigurationDefaultCreate(ctx context.Context, d *sch
 		}
 	}
 
-	environment.DefaultProviderConfigurations = append(environment.DefaultProviderConfigurations, &scalr.ProviderConfiguration{ID: providerConfiguration.ID})
+	defaultProviderConfigurations := make([]*scalr.ProviderConfiguration, 0, len(environment.DefaultProviderConfigurations)+1)
+	defaultProviderConfigurations = append(defaultProviderConfigurations, environment.DefaultProviderConfigurations...)
+	defaultProviderConfigurations = append(defaultProviderConfigurations, &scalr.ProviderConfiguration{ID: providerConfiguration.ID})
 	updateOpts := scalr.EnvironmentUpdateOptions{
-		DefaultProviderConfigurations: environment.DefaultProviderConfigurations,
+		DefaultProviderConfigurations: defaultProviderConfigurations,
 		PolicyGroups:                  environment.PolicyGroups,
 		CloudCredentials:              environment.CloudCredentials,
 	}
@@ -148,12 +150,13 @@ func resourceScalrProviderConfigurationDefaultDelete(ctx context.Context, d *sch
 	}
 
 	found := false
-	for i, pc := range environment.DefaultProviderConfigurations {
+	defaultProviderConfigurations := make([]*scalr.ProviderConfiguration, 0)
+	for _, pc := range environment.DefaultProviderConfigurations {
 		if pc.ID == providerConfigurationID {
-			environment.DefaultProviderConfigurations = append(environment.DefaultProviderConfigurations[:i], environment.DefaultProviderConfigurations[i+1:]...)
 			found = true
-			break
+			continue
 		}
+		defaultProviderConfigurations = append(defaultProviderConfigurations, pc)
 	}
 
 	if !found {
@@ -161,7 +164,7 @@ func resourceScalrProviderConfigurationDefaultDelete(ctx context.Context, d *sch
 	}
 
 	updateOpts := scalr.EnvironmentUpdateOptions{
-		DefaultProviderConfigurations: environment.DefaultProviderConfigurations,
+		DefaultProviderConfigurations: defaultProviderConfigurations,
 		PolicyGroups:                  environment.PolicyGroups,
 		CloudCredentials:              environment.CloudCredentials,
 	}
@@ -0,0 +1,251 @@
+package scalr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/scalr/go-scalr"
+)
+
+func resourceScalrProviderConfigurationWorkspaceDefaults() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceScalrProviderConfigurationWorkspaceDefaultsCreate,
+		ReadContext:   resourceScalrProviderConfigurationWorkspaceDefaultsRead,
+		UpdateContext: resourceScalrProviderConfigurationWorkspaceDefaultsUpdate,
+		DeleteContext: resourceScalrProviderConfigurationWorkspaceDefaultsDelete,
+
+		Schema: map[string]*schema.Schema{
+			"environment_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"workspace_names": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				AtLeastOneOf: []string{"tag_ids"},
+			},
+			"tag_ids": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"provider_configuration": {
+				Type:     schema.TypeSet,
+				Required: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"alias": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"workspace_ids": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"link_ids": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+// matchedWorkspaces returns the workspaces of the environment that match
+// the name and tag filters. A workspace matches if its name is listed
+// in `workspace_names` (or it contains "*"), and it has any of the tags
+// from `tag_ids`. An empty filter matches all workspaces.
+func matchedWorkspaces(ctx context.Context, d *schema.ResourceData, scalrClient *scalr.Client) ([]*scalr.Workspace, error) {
+	names := make(map[string]bool)
+	for _, name := range d.Get("workspace_names").([]interface{}) {
+		names[name.(string)] = true
+	}
+	tagIDs := d.Get("tag_ids").(*schema.Set)
+
+	return listWorkspaces(ctx, scalrClient, d.Get("environment_id").(string), func(w *scalr.Workspace) bool {
+		if len(names) > 0 && !names["*"] && !names[w.Name] {
+			return false
+		}
+		if tagIDs.Len() == 0 {
+			return true
+		}
+		for _, tag := range w.Tags {
+			if tagIDs.Contains(tag.ID) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// applyProviderConfigurationWorkspaceDefaults links the expected provider
+// configurations to all matched workspaces. The links created by the resource
+// are tracked in `link_ids`: those that are no longer expected, or that belong
+// to workspaces that stopped matching the filter, are deleted. Links that
+// existed before are never deleted.
+func applyProviderConfigurationWorkspaceDefaults(
+	ctx context.Context, d *schema.ResourceData, scalrClient *scalr.Client, expected []interface{},
+) diag.Diagnostics {
+	expectedLinks := expandProviderConfigurationLinks(expected)
+
+	stale := make(map[string]bool)
+	for _, id := range d.Get("link_ids").(*schema.Set).List() {
+		stale[id.(string)] = true
+	}
+	linkIDs := make([]string, 0)
+	// The tracked links are saved even if the apply fails halfway,
+	// so that the links created so far are not lost.
+	defer func() {
+		for id := range stale {
+			linkIDs = append(linkIDs, id)
+		}
+		_ = d.Set("link_ids", linkIDs)
+	}()
+
+	workspaces, err := matchedWorkspaces(ctx, d, scalrClient)
+	if err != nil {
+		return diag.Errorf("Error retrieving workspaces: %v", err)
+	}
+
+	// Only the links created by the resource are deleted.
+	owned := func(link *scalr.ProviderConfigurationLink) bool {
+		return stale[link.ID]
+	}
+	for _, w := range workspaces {
+		log.Printf("[DEBUG] Sync provider configuration links of workspace: %s", w.ID)
+		result, err := syncProviderConfigurationLinks(ctx, scalrClient, w.ID, expectedLinks, owned)
+		for _, id := range result.kept {
+			if stale[id] {
+				delete(stale, id)
+				linkIDs = append(linkIDs, id)
+			}
+		}
+		for _, id := range result.deleted {
+			delete(stale, id)
+		}
+		linkIDs = append(linkIDs, result.created...)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if err := deleteProviderConfigurationLinks(ctx, scalrClient, stale); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// deleteProviderConfigurationLinks deletes the provider configuration links
+// and removes them from the set. Links that are already gone are skipped.
+func deleteProviderConfigurationLinks(ctx context.Context, scalrClient *scalr.Client, linkIDs map[string]bool) error {
+	for id := range linkIDs {
+		log.Printf("[DEBUG] Delete provider configuration link: %s", id)
+		err := scalrClient.ProviderConfigurationLinks.Delete(ctx, id)
+		if err != nil && !errors.Is(err, scalr.ErrResourceNotFound) {
+			return fmt.Errorf("Error removing provider configuration link %s: %v", id, err)
+		}
+		delete(linkIDs, id)
+	}
+	return nil
+}
+
+func resourceScalrProviderConfigurationWorkspaceDefaultsCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	// The ID is set beforehand, so that the links created by a failed
+	// apply are kept in the state and deleted along with the resource.
+	d.SetId(resource.UniqueId())
+	providerConfigurations := d.Get("provider_configuration").(*schema.Set).List()
+	diags := applyProviderConfigurationWorkspaceDefaults(ctx, d, scalrClient, providerConfigurations)
+	if diags.HasError() {
+		return diags
+	}
+
+	return resourceScalrProviderConfigurationWorkspaceDefaultsRead(ctx, d, meta)
+}
+
+func resourceScalrProviderConfigurationWorkspaceDefaultsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+	environmentID := d.Get("environment_id").(string)
+
+	log.Printf("[DEBUG] Read workspaces matched in environment: %s", environmentID)
+	workspaces, err := matchedWorkspaces(ctx, d, scalrClient)
+	if err != nil {
+		return diag.Errorf("Error retrieving workspaces: %v", err)
+	}
+
+	// Only keep the provider configurations linked to every matched
+	// workspace, so that a missing link shows up as a change to apply.
+	providerConfigurations := d.Get("provider_configuration").(*schema.Set).List()
+	workspaceIDs := make([]string, 0)
+	for _, w := range workspaces {
+		workspaceIDs = append(workspaceIDs, w.ID)
+
+		links, err := getProviderConfigurationWorkspaceLinks(ctx, scalrClient, w.ID)
+		if err != nil {
+			return diag.Errorf("Error reading provider configuration links of workspace %s: %v", w.ID, err)
+		}
+		linked := make(map[string]bool)
+		for _, link := range links {
+			linked[providerConfigurationLinkKey(link.ProviderConfiguration.ID, link.Alias)] = true
+		}
+
+		present := make([]interface{}, 0)
+		for _, v := range providerConfigurations {
+			pcfg := v.(map[string]interface{})
+			if linked[providerConfigurationLinkKey(pcfg["id"].(string), pcfg["alias"].(string))] {
+				present = append(present, pcfg)
+			}
+		}
+		providerConfigurations = present
+	}
+
+	_ = d.Set("provider_configuration", providerConfigurations)
+	_ = d.Set("workspace_ids", workspaceIDs)
+
+	return nil
+}
+
+func resourceScalrProviderConfigurationWorkspaceDefaultsUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	providerConfigurations := d.Get("provider_configuration").(*schema.Set).List()
+	diags := applyProviderConfigurationWorkspaceDefaults(ctx, d, scalrClient, providerConfigurations)
+	if diags.HasError() {
+		return diags
+	}
+
+	return resourceScalrProviderConfigurationWorkspaceDefaultsRead(ctx, d, meta)
+}
+
+func resourceScalrProviderConfigurationWorkspaceDefaultsDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	linkIDs := make(map[string]bool)
+	for _, id := range d.Get("link_ids").(*schema.Set).List() {
+		linkIDs[id.(string)] = true
+	}
+
+	log.Printf("[DEBUG] Delete provider configuration links created by: %s", d.Id())
+	if err := deleteProviderConfigurationLinks(ctx, scalrClient, linkIDs); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
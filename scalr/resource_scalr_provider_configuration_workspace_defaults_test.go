@@ -0,0 +1,321 @@
+package scalr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/scalr/go-scalr"
+)
+
+func TestAccProviderConfigurationWorkspaceDefaults_basic(t *testing.T) {
+	rInt := GetRandomInteger()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckProviderConfigurationWorkspaceDefaultsDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfigurationWorkspaceDefaultsConfig(rInt, `tag_ids = [scalr_tag.test.id]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"scalr_provider_configuration_workspace_defaults.test", "workspace_ids.#", "1"),
+					resource.TestCheckTypeSetElemAttrPair(
+						"scalr_provider_configuration_workspace_defaults.test", "workspace_ids.*",
+						"scalr_workspace.tagged", "id"),
+					resource.TestCheckResourceAttr(
+						"scalr_provider_configuration_workspace_defaults.test", "provider_configuration.#", "1"),
+					testAccCheckWorkspaceLinkedTo("scalr_workspace.tagged", "scalr_provider_configuration.test", true),
+					testAccCheckWorkspaceLinkedTo("scalr_workspace.other", "scalr_provider_configuration.test", false),
+				),
+			},
+			{
+				Config: testAccProviderConfigurationWorkspaceDefaultsConfig(rInt, `workspace_names = ["*"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"scalr_provider_configuration_workspace_defaults.test", "workspace_ids.#", "2"),
+					testAccCheckWorkspaceLinkedTo("scalr_workspace.tagged", "scalr_provider_configuration.test", true),
+					testAccCheckWorkspaceLinkedTo("scalr_workspace.other", "scalr_provider_configuration.test", true),
+				),
+			},
+		},
+	})
+}
+
+func TestProviderConfigurationWorkspaceDefaults_reconcile(t *testing.T) {
+	client := testScalrClient(t)
+
+	env, err := client.Environments.Create(ctx, scalr.EnvironmentCreateOptions{
+		Name:    scalr.String("test-env"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating environment: %v", err)
+	}
+	tag, err := client.Tags.Create(ctx, scalr.TagCreateOptions{
+		Name:    scalr.String("defaults"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating tag: %v", err)
+	}
+	var pcfgIDs []string
+	for _, name := range []string{"aws", "google"} {
+		pcfg, err := client.ProviderConfigurations.Create(ctx, scalr.ProviderConfigurationCreateOptions{
+			Name:         scalr.String(name),
+			ProviderName: scalr.String(name),
+			Account:      &scalr.Account{ID: defaultAccount},
+		})
+		if err != nil {
+			t.Fatalf("error creating provider configuration: %v", err)
+		}
+		pcfgIDs = append(pcfgIDs, pcfg.ID)
+	}
+	createWorkspace := func(name string, tags ...*scalr.Tag) string {
+		ws, err := client.Workspaces.Create(ctx, scalr.WorkspaceCreateOptions{
+			Name:        scalr.String(name),
+			Environment: &scalr.Environment{ID: env.ID},
+			Tags:        tags,
+		})
+		if err != nil {
+			t.Fatalf("error creating workspace: %v", err)
+		}
+		return ws.ID
+	}
+	linkedTo := func(workspaceID string) []string {
+		links, err := getProviderConfigurationWorkspaceLinks(ctx, client, workspaceID)
+		if err != nil {
+			t.Fatalf("error reading links: %v", err)
+		}
+		ids := make([]string, 0)
+		for _, link := range links {
+			ids = append(ids, link.ProviderConfiguration.ID+"/"+link.Alias)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	tagged := createWorkspace("tagged", &scalr.Tag{ID: tag.ID})
+	other := createWorkspace("other")
+	preexisting := createWorkspace("preexisting", &scalr.Tag{ID: tag.ID})
+	// Links not created by the resource must be left intact.
+	for _, link := range []struct {
+		workspaceID string
+		options     scalr.ProviderConfigurationLinkCreateOptions
+	}{
+		{other, scalr.ProviderConfigurationLinkCreateOptions{
+			ProviderConfiguration: &scalr.ProviderConfiguration{ID: pcfgIDs[1]},
+		}},
+		{preexisting, scalr.ProviderConfigurationLinkCreateOptions{
+			ProviderConfiguration: &scalr.ProviderConfiguration{ID: pcfgIDs[0]},
+			Alias:                 scalr.String("east"),
+		}},
+	} {
+		if _, err := client.ProviderConfigurationLinks.Create(ctx, link.workspaceID, link.options); err != nil {
+			t.Fatalf("error creating link: %v", err)
+		}
+	}
+
+	r := resourceScalrProviderConfigurationWorkspaceDefaults()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"environment_id": env.ID,
+		"tag_ids":        []interface{}{tag.ID},
+		"provider_configuration": []interface{}{
+			map[string]interface{}{"id": pcfgIDs[0], "alias": "east"},
+		},
+	})
+	if diags := r.CreateContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if n := d.Get("workspace_ids").(*schema.Set).Len(); n != 2 {
+		t.Fatalf("expected 2 matched workspaces, got %d", n)
+	}
+	if n := d.Get("link_ids").(*schema.Set).Len(); n != 1 {
+		t.Fatalf("expected only the created link to be tracked, got %d", n)
+	}
+	if got := linkedTo(tagged); len(got) != 1 || got[0] != pcfgIDs[0]+"/east" {
+		t.Fatalf("expected workspace %s to be linked, got %v", tagged, got)
+	}
+	if got := linkedTo(other); len(got) != 1 || got[0] != pcfgIDs[1]+"/" {
+		t.Fatalf("expected links of workspace %s to be left intact, got %v", other, got)
+	}
+
+	// A new matching workspace is reported as drift.
+	added := createWorkspace("added", &scalr.Tag{ID: tag.ID})
+	if diags := r.ReadContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if n := d.Get("workspace_ids").(*schema.Set).Len(); n != 3 {
+		t.Fatalf("expected 3 matched workspaces, got %d", n)
+	}
+	if n := d.Get("provider_configuration").(*schema.Set).Len(); n != 0 {
+		t.Fatalf("expected the missing link to be reported as drift, got %d configurations", n)
+	}
+
+	// Applying the configuration reconciles the drift.
+	providerConfigurations := []interface{}{
+		map[string]interface{}{"id": pcfgIDs[0], "alias": "east"},
+	}
+	d = r.Data(d.State())
+	_ = d.Set("provider_configuration", providerConfigurations)
+	if diags := r.UpdateContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	for _, id := range []string{tagged, added, preexisting} {
+		if got := linkedTo(id); len(got) != 1 || got[0] != pcfgIDs[0]+"/east" {
+			t.Fatalf("expected workspace %s to be linked, got %v", id, got)
+		}
+	}
+	if n := d.Get("provider_configuration").(*schema.Set).Len(); n != 1 {
+		t.Fatalf("expected the drift to be reconciled, got %d configurations", n)
+	}
+	if n := d.Get("link_ids").(*schema.Set).Len(); n != 2 {
+		t.Fatalf("expected 2 tracked links, got %d", n)
+	}
+
+	// Workspaces that stop matching the filter are unlinked.
+	d = r.Data(d.State())
+	_ = d.Set("workspace_names", []interface{}{"tagged", "preexisting"})
+	_ = d.Set("provider_configuration", providerConfigurations)
+	if diags := r.UpdateContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if got := linkedTo(added); len(got) != 0 {
+		t.Fatalf("expected workspace %s to be unlinked, got %v", added, got)
+	}
+	if got := linkedTo(tagged); len(got) != 1 {
+		t.Fatalf("expected workspace %s to stay linked, got %v", tagged, got)
+	}
+	if n := d.Get("link_ids").(*schema.Set).Len(); n != 1 {
+		t.Fatalf("expected 1 tracked link, got %d", n)
+	}
+
+	if diags := r.DeleteContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if got := linkedTo(tagged); len(got) != 0 {
+		t.Fatalf("expected workspace %s to be unlinked, got %v", tagged, got)
+	}
+	for _, id := range []string{other, preexisting} {
+		if got := linkedTo(id); len(got) != 1 {
+			t.Fatalf("expected links of workspace %s to be left intact, got %v", id, got)
+		}
+	}
+}
+
+func testAccCheckWorkspaceLinkedTo(workspace, providerConfiguration string, linked bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		ws, ok := s.RootModule().Resources[workspace]
+		if !ok {
+			return fmt.Errorf("Not found: %s", workspace)
+		}
+		pcfg, ok := s.RootModule().Resources[providerConfiguration]
+		if !ok {
+			return fmt.Errorf("Not found: %s", providerConfiguration)
+		}
+
+		client := testAccProvider.Meta().(*scalr.Client)
+
+		links, err := getProviderConfigurationWorkspaceLinks(ctx, client, ws.Primary.ID)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, link := range links {
+			if link.ProviderConfiguration.ID == pcfg.Primary.ID {
+				found = true
+			}
+		}
+		if found != linked {
+			return fmt.Errorf("Expected workspace %s linked to %s: %t", ws.Primary.ID, pcfg.Primary.ID, linked)
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckProviderConfigurationWorkspaceDefaultsDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*scalr.Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "scalr_provider_configuration_workspace_defaults" {
+			continue
+		}
+
+		for k, workspaceID := range rs.Primary.Attributes {
+			if k == "workspace_ids.#" || !strings.HasPrefix(k, "workspace_ids.") {
+				continue
+			}
+			links, err := getProviderConfigurationWorkspaceLinks(ctx, client, workspaceID)
+			if err != nil {
+				// The workspace may be destroyed already.
+				continue
+			}
+			if len(links) != 0 {
+				return fmt.Errorf("Workspace %s still has provider configuration links", workspaceID)
+			}
+		}
+	}
+
+	return nil
+}
+
+func testAccProviderConfigurationWorkspaceDefaultsConfig(rInt int, filter string) string {
+	return fmt.Sprintf(`
+locals {
+  account_id = "%[1]s"
+}
+
+resource "scalr_environment" "test" {
+  name       = "test-env-%[2]d"
+  account_id = local.account_id
+}
+
+resource "scalr_tag" "test" {
+  name       = "test-tag-%[2]d"
+  account_id = local.account_id
+}
+
+resource "scalr_workspace" "tagged" {
+  name           = "test-ws-tagged-%[2]d"
+  environment_id = scalr_environment.test.id
+  tag_ids        = [scalr_tag.test.id]
+}
+
+resource "scalr_workspace" "other" {
+  name           = "test-ws-other-%[2]d"
+  environment_id = scalr_environment.test.id
+}
+
+resource "scalr_provider_configuration" "test" {
+  name         = "test-%[2]d"
+  account_id   = local.account_id
+  environments = [scalr_environment.test.id]
+  custom {
+    provider_name = "kubernetes"
+    argument {
+      name  = "host"
+      value = "my-host"
+    }
+  }
+}
+
+resource "scalr_provider_configuration_workspace_defaults" "test" {
+  environment_id = scalr_environment.test.id
+  %[3]s
+
+  provider_configuration {
+    id = scalr_provider_configuration.test.id
+  }
+
+  depends_on = [scalr_workspace.tagged, scalr_workspace.other]
+}
+`, defaultAccount, rInt, filter)
+}
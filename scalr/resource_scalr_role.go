@@ -8,6 +8,7 @@ import (
 	"log"
 	"reflect"
 	"sort"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/scalr/go-scalr"
@@ -19,6 +20,7 @@ func resourceScalrRole() *schema.Resource {
 		ReadContext:   resourceScalrRoleRead,
 		UpdateContext: resourceScalrRoleUpdate,
 		DeleteContext: resourceScalrRoleDelete,
+		CustomizeDiff: validateRolePermissions,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -64,6 +66,75 @@ func resourceScalrRole() *schema.Resource {
 	}
 }
 
+// validateRolePermissions checks the permissions of a role against the
+// catalog of the server, so that a typo fails the plan rather than the apply.
+// The permissions not known yet are skipped, and a failure to read the
+// catalog does not block the plan, it is logged and reported as a warning
+// on apply.
+func validateRolePermissions(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() != "" && !d.HasChange("permissions") {
+		return nil
+	}
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() {
+		return nil
+	}
+	raw := config.GetAttr("permissions")
+	if raw.IsNull() || !raw.IsKnown() {
+		return nil
+	}
+	ids := make([]string, 0)
+	for it := raw.ElementIterator(); it.Next(); {
+		_, v := it.Element()
+		if v.IsKnown() && !v.IsNull() && v.AsString() != "" {
+			ids = append(ids, v.AsString())
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	catalog, err := readPermissionsCatalog(ctx, meta.(*scalr.Client))
+	if err != nil {
+		log.Printf("[WARN] Cannot validate the permissions of the role: %v", err)
+		return nil
+	}
+	if unknown := unknownPermissions(catalog, ids); len(unknown) > 0 {
+		return fmt.Errorf("unknown permissions: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// readPermissionsCatalog reads the catalog of the permissions
+// the roles are validated against.
+func readPermissionsCatalog(ctx context.Context, scalrClient *scalr.Client) ([]*permission, error) {
+	catalog, err := listPermissions(ctx, scalrClient)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving permissions: %v", err)
+	}
+	if len(catalog) == 0 {
+		return nil, errors.New("the permissions catalog is empty")
+	}
+	return catalog, nil
+}
+
+// rolePermissionsWarning warns that the permissions of the role were not
+// validated during the plan, as the catalog cannot be read.
+func rolePermissionsWarning(ctx context.Context, scalrClient *scalr.Client, name string) diag.Diagnostics {
+	if _, err := readPermissionsCatalog(ctx, scalrClient); err != nil {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Permissions of role %s are not validated", name),
+			Detail: fmt.Sprintf(
+				"The permissions cannot be checked against the catalog of the server: %v. "+
+					"Use the scalr_permissions data source to expand the wildcard permissions from the catalog.",
+				err,
+			),
+		}}
+	}
+	return nil
+}
+
 func parsePermissionDefinitions(d *schema.ResourceData) ([]*scalr.Permission, error) {
 	permissions := make([]*scalr.Permission, 0)
 
@@ -109,7 +180,8 @@ func resourceScalrRoleCreate(ctx context.Context, d *schema.ResourceData, meta i
 			"Error creating role %s for account %s: %v", name, accountID, err)
 	}
 	d.SetId(role.ID)
-	return resourceScalrRoleRead(ctx, d, meta)
+	diags := rolePermissionsWarning(ctx, scalrClient, name)
+	return append(diags, resourceScalrRoleRead(ctx, d, meta)...)
 }
 
 func resourceScalrRoleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -167,6 +239,7 @@ func resourceScalrRoleUpdate(ctx context.Context, d *schema.ResourceData, meta i
 
 	id := d.Id()
 
+	var diags diag.Diagnostics
 	if d.HasChange("name") || d.HasChange("description") || d.HasChange("permissions") {
 		permissions, err := parsePermissionDefinitions(d)
 		if err != nil {
@@ -186,9 +259,12 @@ func resourceScalrRoleUpdate(ctx context.Context, d *schema.ResourceData, meta i
 			return diag.Errorf(
 				"Error updating role %s: %v", id, err)
 		}
+		if d.HasChange("permissions") {
+			diags = rolePermissionsWarning(ctx, scalrClient, d.Get("name").(string))
+		}
 	}
 
-	return resourceScalrRoleRead(ctx, d, meta)
+	return append(diags, resourceScalrRoleRead(ctx, d, meta)...)
 }
 
 func resourceScalrRoleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
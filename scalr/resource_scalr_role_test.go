@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"log"
 	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/scalr/go-scalr"
@@ -134,6 +136,49 @@ func TestAccScalrRole_import(t *testing.T) {
 	})
 }
 
+func TestRole_validatePermissions(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+	client, err := newScalrClient(&scalr.Config{Address: server.Address(), Token: testAPIToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := resourceScalrRole()
+	plan := func(permissions ...interface{}) error {
+		_, _, err := testResourcePlan(t, r, nil, map[string]interface{}{
+			"name":        "test-role",
+			"account_id":  defaultAccount,
+			"permissions": permissions,
+		}, client)
+		return err
+	}
+
+	// The permissions are not checked against an empty catalog.
+	if err := plan("workspaces:updtae"); err != nil {
+		t.Fatalf("unexpected error without a catalog: %v", err)
+	}
+
+	// The permissions not validated during the plan are reported on apply.
+	diags := rolePermissionsWarning(ctx, client, "test-role")
+	if len(diags) != 1 || diags[0].Severity != diag.Warning ||
+		!strings.Contains(diags[0].Detail, "the permissions catalog is empty") {
+		t.Fatalf("expected a warning about the permissions not validated, got %v", diags)
+	}
+
+	testPermissions(server)
+	if err := plan("workspaces:read", "workspaces:update", "*:update"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diags := rolePermissionsWarning(ctx, client, "test-role"); len(diags) != 0 {
+		t.Fatalf("unexpected warning: %v", diags)
+	}
+	err = plan("workspaces:read", "workspaces:updtae", "unknown:permission", "*:import")
+	expected := "unknown permissions: workspaces:updtae (did you mean workspaces:update?), unknown:permission, *:import"
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Fatalf("expected error %q, got %v", expected, err)
+	}
+}
+
 func testAccCheckScalrRoleExists(resId string, role *scalr.Role) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		scalrClient := testAccProvider.Meta().(*scalr.Client)
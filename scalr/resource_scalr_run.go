@@ -0,0 +1,323 @@
+package scalr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/scalr/go-scalr"
+)
+
+// run represents a Scalr run with the attributes go-scalr does not expose.
+type run struct {
+	ID        string          `jsonapi:"primary,runs"`
+	Source    scalr.RunSource `jsonapi:"attr,source"`
+	Message   string          `jsonapi:"attr,message"`
+	IsDestroy bool            `jsonapi:"attr,is-destroy"`
+	IsDry     bool            `jsonapi:"attr,is-dry"`
+	Status    scalr.RunStatus `jsonapi:"attr,status"`
+
+	Plan      *runPlan         `jsonapi:"relation,plan"`
+	Workspace *scalr.Workspace `jsonapi:"relation,workspace"`
+}
+
+// runPlan represents the plan phase of a run.
+type runPlan struct {
+	ID                   string `jsonapi:"primary,plans"`
+	ResourceAdditions    int    `jsonapi:"attr,resource-additions"`
+	ResourceChanges      int    `jsonapi:"attr,resource-changes"`
+	ResourceDestructions int    `jsonapi:"attr,resource-destructions"`
+}
+
+// runCreateOptions represents the options for queueing a new run.
+type runCreateOptions struct {
+	ID        string  `jsonapi:"primary,runs"`
+	IsDestroy *bool   `jsonapi:"attr,is-destroy,omitempty"`
+	IsDry     *bool   `jsonapi:"attr,is-dry,omitempty"`
+	Message   *string `jsonapi:"attr,message,omitempty"`
+
+	Workspace *scalr.Workspace `jsonapi:"relation,workspace"`
+}
+
+// Statuses a run passes through before it is finished.
+var runPendingStatuses = []string{
+	string(scalr.RunPending),
+	string(scalr.RunPlanQueued),
+	string(scalr.RunPlanning),
+	string(scalr.RunCostEstimating),
+	string(scalr.RunPolicyChecking),
+	string(scalr.RunConfirmed),
+	string(scalr.RunApplyQueued),
+	string(scalr.RunApplying),
+}
+
+// Statuses in which a run waits for the apply to be confirmed,
+// unless the workspace applies runs automatically.
+var runConfirmableStatuses = []string{
+	string(scalr.RunPlanned),
+	string(scalr.RunCostEstimated),
+	string(scalr.RunPolicyChecked),
+}
+
+// Statuses of a finished run.
+var runFinalStatuses = []string{
+	string(scalr.RunApplied),
+	string(scalr.RunPlannedAndFinished),
+	string(scalr.RunErrored),
+	string(scalr.RunDiscarded),
+	string(scalr.RunCanceled),
+}
+
+// Statuses in which a run waits for a failed policy check to be
+// overridden or for the run to be discarded. The policy checks are
+// never overridden automatically, even if the apply is confirmed.
+var runPolicyActionStatuses = []string{
+	string(scalr.RunPolicySoftFailed),
+	string(scalr.RunPolicyOverride),
+}
+
+func resourceScalrRun() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceScalrRunCreate,
+		ReadContext:   resourceScalrRunRead,
+		DeleteContext: resourceScalrRunDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"workspace_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"is_destroy": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				ForceNew: true,
+			},
+			"is_dry": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				ForceNew: true,
+			},
+			"message": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"auto_approve": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				ForceNew: true,
+			},
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"source": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"resource_additions": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"resource_changes": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"resource_destructions": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func readRun(ctx context.Context, scalrClient *scalr.Client, runID string) (*run, error) {
+	r := &run{}
+	err := doAPIRequest(ctx, scalrClient, "GET", fmt.Sprintf("runs/%s?include=plan", runID), nil, r)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// waitForRun polls the run until it is finished. When confirm is set, the
+// apply is confirmed once the plan is done, otherwise the polling stops
+// while the run waits for the confirmation. The polling also stops while
+// the run waits for an action on a failed policy check.
+func waitForRun(ctx context.Context, scalrClient *scalr.Client, runID string, awaitsConfirmation, confirm bool, timeout time.Duration) (*run, error) {
+	pending := make([]string, 0)
+	pending = append(pending, runPendingStatuses...)
+	target := make([]string, 0)
+	target = append(target, runFinalStatuses...)
+	target = append(target, runPolicyActionStatuses...)
+	if awaitsConfirmation && !confirm {
+		target = append(target, runConfirmableStatuses...)
+	} else {
+		pending = append(pending, runConfirmableStatuses...)
+	}
+
+	confirmed := false
+	stateConf := &resource.StateChangeConf{
+		Pending:    pending,
+		Target:     target,
+		Timeout:    timeout,
+		MinTimeout: 5 * time.Second,
+		Refresh: func() (interface{}, string, error) {
+			r, err := readRun(ctx, scalrClient, runID)
+			if err != nil {
+				return nil, "", err
+			}
+			status := string(r.Status)
+			log.Printf("[DEBUG] Run %s status: %s", runID, status)
+
+			if awaitsConfirmation && confirm && !confirmed && stringInSlice(status, runConfirmableStatuses) {
+				log.Printf("[DEBUG] Confirm apply of run %s", runID)
+				err := doAPIRequest(ctx, scalrClient, "POST", fmt.Sprintf("runs/%s/actions/apply", runID), nil, nil)
+				if err != nil {
+					return nil, "", fmt.Errorf("error confirming apply: %v", err)
+				}
+				confirmed = true
+			}
+			return r, status, nil
+		},
+	}
+
+	r, err := stateConf.WaitForStateContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.(*run), nil
+}
+
+func resourceScalrRunCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	workspaceID := d.Get("workspace_id").(string)
+	isDry := d.Get("is_dry").(bool)
+
+	log.Printf("[DEBUG] Read configuration of workspace: %s", workspaceID)
+	workspace, err := scalrClient.Workspaces.ReadByID(ctx, workspaceID)
+	if err != nil {
+		return diag.Errorf("Error reading configuration of workspace %s: %v", workspaceID, err)
+	}
+
+	options := runCreateOptions{
+		IsDestroy: scalr.Bool(d.Get("is_destroy").(bool)),
+		IsDry:     scalr.Bool(isDry),
+		Workspace: &scalr.Workspace{ID: workspaceID},
+	}
+	if message, ok := d.GetOk("message"); ok {
+		options.Message = scalr.String(message.(string))
+	}
+
+	log.Printf("[DEBUG] Create run in workspace: %s", workspaceID)
+	r := &run{}
+	err = doAPIRequest(ctx, scalrClient, "POST", "runs", &options, r)
+	if err != nil {
+		return diag.Errorf("Error creating run in workspace %s: %v", workspaceID, err)
+	}
+	d.SetId(r.ID)
+
+	awaitsConfirmation := !isDry && !workspace.AutoApply
+	r, err = waitForRun(
+		ctx, scalrClient, r.ID, awaitsConfirmation, d.Get("auto_approve").(bool), d.Timeout(schema.TimeoutCreate),
+	)
+	if err != nil {
+		return diag.Errorf("Error waiting for run %s to finish: %v", d.Id(), err)
+	}
+
+	if stringInSlice(string(r.Status), runConfirmableStatuses) {
+		diags := resourceScalrRunRead(ctx, d, meta)
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Run %s awaits the confirmation of the apply", r.ID),
+			Detail: fmt.Sprintf(
+				"The run stopped with status %s, as workspace %s does not apply runs automatically. "+
+					"Confirm or discard the run in Scalr, or set auto_approve to confirm the apply from Terraform.",
+				r.Status, workspaceID,
+			),
+		})
+	}
+
+	if stringInSlice(string(r.Status), runPolicyActionStatuses) {
+		diags := resourceScalrRunRead(ctx, d, meta)
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Run %s awaits an action on the failed policy check", r.ID),
+			Detail: fmt.Sprintf(
+				"The run stopped with status %s. Policy checks are not overridden by auto_approve. "+
+					"Override the policy check or discard the run in Scalr.",
+				r.Status,
+			),
+		})
+	}
+
+	switch r.Status {
+	case scalr.RunErrored, scalr.RunDiscarded, scalr.RunCanceled:
+		diags := resourceScalrRunRead(ctx, d, meta)
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Run %s finished with status %s", r.ID, r.Status),
+		})
+	}
+
+	return resourceScalrRunRead(ctx, d, meta)
+}
+
+func resourceScalrRunRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+	id := d.Id()
+
+	log.Printf("[DEBUG] Read run: %s", id)
+	r, err := readRun(ctx, scalrClient, id)
+	if err != nil {
+		if errors.Is(err, scalr.ErrResourceNotFound) {
+			log.Printf("[DEBUG] Run %s not found", id)
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("Error reading run %s: %v", id, err)
+	}
+
+	if r.Workspace != nil {
+		_ = d.Set("workspace_id", r.Workspace.ID)
+	}
+	_ = d.Set("is_destroy", r.IsDestroy)
+	_ = d.Set("is_dry", r.IsDry)
+	_ = d.Set("message", r.Message)
+	_ = d.Set("status", r.Status)
+	_ = d.Set("source", r.Source)
+
+	var additions, changes, destructions int
+	if r.Plan != nil {
+		additions = r.Plan.ResourceAdditions
+		changes = r.Plan.ResourceChanges
+		destructions = r.Plan.ResourceDestructions
+	}
+	_ = d.Set("resource_additions", additions)
+	_ = d.Set("resource_changes", changes)
+	_ = d.Set("resource_destructions", destructions)
+
+	return nil
+}
+
+func resourceScalrRunDelete(_ context.Context, d *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	// Runs are kept in the workspace history, so there is nothing to delete.
+	log.Printf("[DEBUG] Remove run %s from the state", d.Id())
+	return nil
+}
@@ -0,0 +1,289 @@
+package scalr
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/scalr/go-scalr"
+)
+
+func TestAccScalrRun_dry(t *testing.T) {
+	rInt := GetRandomInteger()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckScalrEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccScalrRunConfig(rInt, false, `
+  is_dry  = true
+  message = "Plan from Terraform"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckScalrRunExists("scalr_run.test"),
+					resource.TestCheckResourceAttrPair("scalr_run.test", "workspace_id", "scalr_workspace.test", "id"),
+					resource.TestCheckResourceAttr("scalr_run.test", "is_dry", "true"),
+					resource.TestCheckResourceAttr("scalr_run.test", "is_destroy", "false"),
+					resource.TestCheckResourceAttr("scalr_run.test", "message", "Plan from Terraform"),
+					resource.TestCheckResourceAttr("scalr_run.test", "status", string(scalr.RunPlannedAndFinished)),
+					resource.TestCheckResourceAttrSet("scalr_run.test", "source"),
+					resource.TestCheckResourceAttrSet("scalr_run.test", "resource_additions"),
+					resource.TestCheckResourceAttrSet("scalr_run.test", "resource_changes"),
+					resource.TestCheckResourceAttrSet("scalr_run.test", "resource_destructions"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccScalrRun_autoApprove(t *testing.T) {
+	rInt := GetRandomInteger()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckScalrEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccScalrRunConfig(rInt, false, `
+  auto_approve = true`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckScalrRunExists("scalr_run.test"),
+					resource.TestCheckResourceAttr("scalr_run.test", "status", string(scalr.RunApplied)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccScalrRun_workspaceAutoApply(t *testing.T) {
+	rInt := GetRandomInteger()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckScalrEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccScalrRunConfig(rInt, true, ""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckScalrRunExists("scalr_run.test"),
+					resource.TestCheckResourceAttr("scalr_run.test", "status", string(scalr.RunApplied)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccScalrRun_import(t *testing.T) {
+	rInt := GetRandomInteger()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckScalrEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccScalrRunConfig(rInt, false, `
+  is_dry = true`),
+			},
+			{
+				ResourceName:            "scalr_run.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"auto_approve"},
+			},
+		},
+	})
+}
+
+func TestWaitForRun(t *testing.T) {
+	client := testScalrClient(t)
+
+	env, err := client.Environments.Create(ctx, scalr.EnvironmentCreateOptions{
+		Name:    scalr.String("test-env"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating environment: %v", err)
+	}
+	ws, err := client.Workspaces.Create(ctx, scalr.WorkspaceCreateOptions{
+		Name:        scalr.String("test-ws"),
+		Environment: &scalr.Environment{ID: env.ID},
+	})
+	if err != nil {
+		t.Fatalf("error creating workspace: %v", err)
+	}
+
+	createRun := func(t *testing.T, isDry bool) *run {
+		r := &run{}
+		options := runCreateOptions{
+			IsDry:     scalr.Bool(isDry),
+			Workspace: &scalr.Workspace{ID: ws.ID},
+		}
+		if err := doAPIRequest(ctx, client, "POST", "runs", &options, r); err != nil {
+			t.Fatalf("error creating run: %v", err)
+		}
+		return r
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		r, err := waitForRun(ctx, client, createRun(t, true).ID, false, false, time.Minute)
+		if err != nil {
+			t.Fatalf("error waiting for run: %v", err)
+		}
+		if r.Status != scalr.RunPlannedAndFinished {
+			t.Fatalf("expected status %q, got %q", scalr.RunPlannedAndFinished, r.Status)
+		}
+		if r.Plan == nil || r.Plan.ID == "" {
+			t.Fatal("expected the plan to be included")
+		}
+		if r.Workspace == nil || r.Workspace.ID != ws.ID {
+			t.Fatalf("expected workspace %s, got %v", ws.ID, r.Workspace)
+		}
+	})
+
+	t.Run("awaiting confirmation", func(t *testing.T) {
+		r, err := waitForRun(ctx, client, createRun(t, false).ID, true, false, time.Minute)
+		if err != nil {
+			t.Fatalf("error waiting for run: %v", err)
+		}
+		if r.Status != scalr.RunPlanned {
+			t.Fatalf("expected status %q, got %q", scalr.RunPlanned, r.Status)
+		}
+	})
+
+	t.Run("confirmed", func(t *testing.T) {
+		r, err := waitForRun(ctx, client, createRun(t, false).ID, true, true, time.Minute)
+		if err != nil {
+			t.Fatalf("error waiting for run: %v", err)
+		}
+		if r.Status != scalr.RunApplied {
+			t.Fatalf("expected status %q, got %q", scalr.RunApplied, r.Status)
+		}
+	})
+
+	t.Run("resource awaiting confirmation", func(t *testing.T) {
+		r := resourceScalrRun()
+		d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"workspace_id": ws.ID})
+		diags := r.CreateContext(ctx, d, client)
+		if !diags.HasError() || !strings.Contains(diags[0].Summary, "awaits the confirmation of the apply") {
+			t.Fatalf("expected error for a run awaiting confirmation, got %v", diags)
+		}
+		if d.Id() == "" || d.Get("status") != string(scalr.RunPlanned) {
+			t.Fatalf("expected the run to be kept in the state with status %q, got %q", scalr.RunPlanned, d.Get("status"))
+		}
+	})
+
+	t.Run("without workspace", func(t *testing.T) {
+		r := resourceScalrRun()
+		d := r.Data(&terraform.InstanceState{ID: "run-without-ws"})
+		server := newTestAPIServer()
+		defer server.Close()
+		c, err := newScalrClient(&scalr.Config{Address: server.Address(), Token: testAPIToken})
+		if err != nil {
+			t.Fatalf("error creating Scalr client: %v", err)
+		}
+		server.put(&testAPIResource{Type: "runs", ID: "run-without-ws", Attributes: map[string]interface{}{"status": "applied"}})
+		if diags := r.ReadContext(ctx, d, c); diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+		if d.Get("status") != string(scalr.RunApplied) {
+			t.Fatalf("expected status %q, got %q", scalr.RunApplied, d.Get("status"))
+		}
+	})
+
+	t.Run("awaiting policy action", func(t *testing.T) {
+		server := newTestAPIServer()
+		defer server.Close()
+		c, err := newScalrClient(&scalr.Config{Address: server.Address(), Token: testAPIToken})
+		if err != nil {
+			t.Fatalf("error creating Scalr client: %v", err)
+		}
+		for _, status := range []scalr.RunStatus{scalr.RunPolicySoftFailed, scalr.RunPolicyOverride} {
+			id := "run-" + string(status)
+			server.put(&testAPIResource{Type: "runs", ID: id, Attributes: map[string]interface{}{"status": string(status)}})
+			r, err := waitForRun(ctx, c, id, true, true, time.Minute)
+			if err != nil {
+				t.Fatalf("error waiting for run: %v", err)
+			}
+			if r.Status != status {
+				t.Fatalf("expected status %q, got %q", status, r.Status)
+			}
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := readRun(ctx, client, "run-not-exists")
+		if !errors.Is(err, scalr.ErrResourceNotFound) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+}
+
+func TestAccScalrRun_failedWorkspace(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource scalr_run test {
+  workspace_id = "ws-not-exists"
+}`,
+				ExpectError: regexp.MustCompile("Error reading configuration of workspace ws-not-exists"),
+			},
+		},
+	})
+}
+
+func testAccCheckScalrRunExists(resId string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		scalrClient := testAccProvider.Meta().(*scalr.Client)
+
+		rs, ok := s.RootModule().Resources[resId]
+		if !ok {
+			return fmt.Errorf("Not found: %s", resId)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No instance ID is set")
+		}
+
+		r, err := readRun(ctx, scalrClient, rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+
+		if r.Workspace == nil || r.Workspace.ID != rs.Primary.Attributes["workspace_id"] {
+			return fmt.Errorf("Run %s does not belong to workspace %s", r.ID, rs.Primary.Attributes["workspace_id"])
+		}
+
+		return nil
+	}
+}
+
+func testAccScalrRunConfig(rInt int, autoApply bool, runArgs string) string {
+	return fmt.Sprintf(`
+resource scalr_environment test {
+  name       = "test-env-run-%[1]d"
+  account_id = "%[2]s"
+}
+
+resource scalr_workspace test {
+  name           = "test-ws-run-%[1]d"
+  environment_id = scalr_environment.test.id
+  auto_apply     = %[3]t
+}
+
+resource scalr_run test {
+  workspace_id = scalr_workspace.test.id%[4]s
+}`, rInt, defaultAccount, autoApply, runArgs)
+}
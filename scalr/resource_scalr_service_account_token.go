@@ -5,17 +5,19 @@ import (
 	"errors"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"log"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/scalr/go-scalr"
 )
 
 func resourceScalrServiceAccountToken() *schema.Resource {
-	return &schema.Resource{
+	r := &schema.Resource{
 		CreateContext: resourceScalrServiceAccountTokenCreate,
 		ReadContext:   resourceScalrServiceAccountTokenRead,
 		UpdateContext: resourceScalrServiceAccountTokenUpdate,
 		DeleteContext: resourceScalrServiceAccountTokenDelete,
+		CustomizeDiff: diffTokenRotation,
 		Schema: map[string]*schema.Schema{
 			"service_account_id": {
 				Type:     schema.TypeString,
@@ -33,6 +35,10 @@ func resourceScalrServiceAccountToken() *schema.Resource {
 			},
 		},
 	}
+	for k, v := range tokenRotationSchema() {
+		r.Schema[k] = v
+	}
+	return r
 }
 
 func resourceScalrServiceAccountTokenCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -87,6 +93,7 @@ func resourceScalrServiceAccountTokenRead(ctx context.Context, d *schema.Resourc
 		for _, at := range atl.Items {
 			if at.ID == id {
 				_ = d.Set("description", at.Description)
+				_ = d.Set("created_at", at.CreatedAt.Format(time.RFC3339))
 				return nil
 			}
 		}
@@ -2,11 +2,17 @@ package scalr
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"log"
+	"strings"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -30,6 +36,7 @@ func resourceScalrVariable() *schema.Resource {
 				}
 				return nil
 			},
+			diffVariableValueHash,
 		),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -66,6 +73,27 @@ func resourceScalrVariable() *schema.Resource {
 				Optional:  true,
 				Default:   "",
 				Sensitive: true,
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					// Write-only values are compared by the hash in CustomizeDiff.
+					return d.Get("value_write_only").(bool)
+				},
+			},
+
+			"value_write_only": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"value_version": {
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+
+			"value_hash": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
 			},
 
 			"category": {
@@ -135,6 +163,109 @@ func resourceScalrVariable() *schema.Resource {
 	}
 }
 
+// variableValueHash returns a salted SHA-256 hash of the value in the
+// format <salt>:<hash>, both hex encoded. It is kept in the state instead
+// of the value of a write-only variable.
+func variableValueHash(value string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("error generating salt: %v", err)
+	}
+	return fmt.Sprintf("%x:%x", salt, saltedSHA256(salt, value)), nil
+}
+
+// variableValueHashMatches reports whether the value matches the hash
+// returned by variableValueHash.
+func variableValueHashMatches(hash, value string) bool {
+	parts := strings.SplitN(hash, ":", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	salt, err := hex.DecodeString(parts[0])
+	if err != nil {
+		return false
+	}
+	sum, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(sum, saltedSHA256(salt, value)) == 1
+}
+
+func saltedSHA256(salt []byte, value string) []byte {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(value))
+	return h.Sum(nil)
+}
+
+// variableConfigValue returns the value of the variable from the configuration,
+// as the value of a write-only variable is not kept in the state and is never
+// part of the diff. The second result is false if the value is not known yet.
+func variableConfigValue(config cty.Value) (string, bool) {
+	if config.IsNull() || !config.IsKnown() {
+		return "", false
+	}
+	value := config.GetAttr("value")
+	if !value.IsKnown() {
+		return "", false
+	}
+	if value.IsNull() {
+		return "", true
+	}
+	return value.AsString(), true
+}
+
+// diffVariableValueHash plans a write of the write-only value if it does not
+// match the hash in the state, or if the value version changes.
+func diffVariableValueHash(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if !d.Get("value_write_only").(bool) {
+		return nil
+	}
+	if !d.Get("sensitive").(bool) {
+		return errors.New("Attribute 'value_write_only' can only be enabled for sensitive variables.")
+	}
+	if d.Id() == "" {
+		return nil
+	}
+
+	if d.HasChange("value_write_only") || d.HasChange("value_version") {
+		return d.SetNewComputed("value_hash")
+	}
+	value, known := variableConfigValue(d.GetRawConfig())
+	if !known || !variableValueHashMatches(d.Get("value_hash").(string), value) {
+		log.Printf("[DEBUG] Value of variable %s does not match the hash in the state", d.Id())
+		return d.SetNewComputed("value_hash")
+	}
+	return nil
+}
+
+// variableValue returns the value to write and, for write-only variables,
+// its hash to keep in the state once the value is written.
+func variableValue(d *schema.ResourceData) (string, string, error) {
+	if !d.Get("value_write_only").(bool) {
+		return d.Get("value").(string), "", nil
+	}
+
+	value, known := variableConfigValue(d.GetRawConfig())
+	if !known {
+		return "", "", errors.New("the value of the write-only variable is not known")
+	}
+	hash, err := variableValueHash(value)
+	if err != nil {
+		return "", "", err
+	}
+	return value, hash, nil
+}
+
+// setVariableValueHash keeps only the hash of a write-only value in the state.
+func setVariableValueHash(d *schema.ResourceData, hash string) {
+	_ = d.Set("value_hash", hash)
+	if hash != "" {
+		_ = d.Set("value", "")
+	}
+}
+
 func resourceScalrVariableCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	scalrClient := meta.(*scalr.Client)
 
@@ -142,10 +273,15 @@ func resourceScalrVariableCreate(ctx context.Context, d *schema.ResourceData, me
 	key := d.Get("key").(string)
 	category := scalr.CategoryType(d.Get("category").(string))
 
+	value, hash, err := variableValue(d)
+	if err != nil {
+		return diag.Errorf("Error creating %s variable %s: %v", category, key, err)
+	}
+
 	// Create a new options struct.
 	options := scalr.VariableCreateOptions{
 		Key:          scalr.String(key),
-		Value:        scalr.String(d.Get("value").(string)),
+		Value:        scalr.String(value),
 		Description:  scalr.String(d.Get("description").(string)),
 		Category:     scalr.Category(category),
 		HCL:          scalr.Bool(d.Get("hcl").(bool)),
@@ -187,6 +323,7 @@ func resourceScalrVariableCreate(ctx context.Context, d *schema.ResourceData, me
 	}
 
 	d.SetId(variable.ID)
+	setVariableValueHash(d, hash)
 
 	return resourceScalrVariableRead(ctx, d, meta)
 }
@@ -240,10 +377,15 @@ func resourceScalrVariableRead(ctx context.Context, d *schema.ResourceData, meta
 func resourceScalrVariableUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	scalrClient := meta.(*scalr.Client)
 
+	value, hash, err := variableValue(d)
+	if err != nil {
+		return diag.Errorf("Error updating variable %s: %v", d.Id(), err)
+	}
+
 	// Create a new options struct.
 	options := scalr.VariableUpdateOptions{
 		Key:          scalr.String(d.Get("key").(string)),
-		Value:        scalr.String(d.Get("value").(string)),
+		Value:        scalr.String(value),
 		HCL:          scalr.Bool(d.Get("hcl").(bool)),
 		Sensitive:    scalr.Bool(d.Get("sensitive").(bool)),
 		Description:  scalr.String(d.Get("description").(string)),
@@ -252,10 +394,11 @@ func resourceScalrVariableUpdate(ctx context.Context, d *schema.ResourceData, me
 	}
 
 	log.Printf("[DEBUG] Update variable: %s", d.Id())
-	_, err := scalrClient.Variables.Update(ctx, d.Id(), options)
+	_, err = scalrClient.Variables.Update(ctx, d.Id(), options)
 	if err != nil {
 		return diag.Errorf("Error updating variable %s: %v", d.Id(), err)
 	}
+	setVariableValueHash(d, hash)
 
 	return resourceScalrVariableRead(ctx, d, meta)
 }
@@ -7,29 +7,37 @@ import (
 	"github.com/scalr/go-scalr"
 )
 
-func testResourceScalrVariableStateDataV0() map[string]interface{} {
+func testResourceScalrVariableStateDataV0(envID string) map[string]interface{} {
 	return map[string]interface{}{
-		"workspace_id": "my-env/a-workspace",
+		"workspace_id": envID + "/a-workspace",
 	}
 }
 
-func testResourceScalrVariableStateDataV1() map[string]interface{} {
+func testResourceScalrVariableStateDataV1(wsID string) map[string]interface{} {
 	return map[string]interface{}{
-		"workspace_id": "ws-123",
+		"workspace_id": wsID,
 	}
 }
 
 func TestResourceScalrVariableStateUpgradeV0(t *testing.T) {
 	client := testScalrClient(t)
-	name := "a-workspace"
-	_, _ = client.Workspaces.Create(context.Background(), scalr.WorkspaceCreateOptions{
-		ID:          "ws-123",
-		Name:        &name,
-		Environment: &scalr.Environment{ID: "my-env"},
+	env, err := client.Environments.Create(context.Background(), scalr.EnvironmentCreateOptions{
+		Name:    scalr.String("my-env"),
+		Account: &scalr.Account{ID: defaultAccount},
 	})
+	if err != nil {
+		t.Fatalf("error creating environment: %v", err)
+	}
+	ws, err := client.Workspaces.Create(context.Background(), scalr.WorkspaceCreateOptions{
+		Name:        scalr.String("a-workspace"),
+		Environment: &scalr.Environment{ID: env.ID},
+	})
+	if err != nil {
+		t.Fatalf("error creating workspace: %v", err)
+	}
 
-	expected := testResourceScalrVariableStateDataV1()
-	actual, err := resourceScalrVariableStateUpgradeV0(ctx, testResourceScalrVariableStateDataV0(), client)
+	expected := testResourceScalrVariableStateDataV1(ws.ID)
+	actual, err := resourceScalrVariableStateUpgradeV0(ctx, testResourceScalrVariableStateDataV0(env.ID), client)
 	assertCorrectState(t, err, actual, expected)
 }
 
@@ -66,7 +74,14 @@ func testResourceScalrVariableStateDataDescriptionV2(varID string) map[string]in
 
 func TestResourceScalrVariableStateUpgradeV2(t *testing.T) {
 	client := testScalrClient(t)
-	variable, _ := client.Variables.Create(context.Background(), scalr.VariableCreateOptions{ID: "var-123"})
+	variable, err := client.Variables.Create(context.Background(), scalr.VariableCreateOptions{
+		Key:      scalr.String("var_key"),
+		Category: scalr.Category(scalr.CategoryTerraform),
+		Account:  &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating variable: %v", err)
+	}
 	expected := testResourceScalrVariableStateDataDescriptionV2(variable.ID)
 	actual, err := resourceScalrVariableStateUpgradeV2(ctx, testResourceScalrVariableStateDataDescriptionV1(variable.ID), client)
 	assertCorrectState(t, err, actual, expected)
@@ -0,0 +1,449 @@
+package scalr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/scalr/go-scalr"
+)
+
+func resourceScalrVariableSet() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceScalrVariableSetCreate,
+		ReadContext:   resourceScalrVariableSetRead,
+		UpdateContext: resourceScalrVariableSetUpdate,
+		DeleteContext: resourceScalrVariableSetDelete,
+		CustomizeDiff: resourceScalrVariableSetCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceScalrVariableSetImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"workspace_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"workspace_id", "environment_id"},
+			},
+			"environment_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"account_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				DefaultFunc: scalrAccountIDDefaultFunc,
+				ForceNew:    true,
+			},
+			"category": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				ValidateFunc: validation.StringInSlice(
+					[]string{
+						string(scalr.CategoryEnv),
+						string(scalr.CategoryTerraform),
+						string(scalr.CategoryShell),
+					},
+					false,
+				),
+			},
+			"variables": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"sensitive_variables": {
+				Type:      schema.TypeMap,
+				Optional:  true,
+				Sensitive: true,
+				Elem:      &schema.Schema{Type: schema.TypeString},
+			},
+			"hcl_keys": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"final_keys": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"force": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"delete_unmanaged": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"variable_ids": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+// variableSetSpec is the expected state of a variable of the set.
+type variableSetSpec struct {
+	value     string
+	sensitive bool
+	hcl       bool
+	final     bool
+}
+
+// variableSetSpecs returns the variables of the set keyed by the variable key.
+func variableSetSpecs(variables, sensitiveVariables map[string]interface{}, hclKeys, finalKeys *schema.Set) map[string]variableSetSpec {
+	specs := make(map[string]variableSetSpec)
+	for k, v := range variables {
+		specs[k] = variableSetSpec{value: v.(string)}
+	}
+	for k, v := range sensitiveVariables {
+		specs[k] = variableSetSpec{value: v.(string), sensitive: true}
+	}
+	for k, spec := range specs {
+		spec.hcl = hclKeys.Contains(k)
+		spec.final = finalKeys.Contains(k)
+		specs[k] = spec
+	}
+	return specs
+}
+
+func resourceScalrVariableSetCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Get("category").(string) == string(scalr.CategoryTerraform) && d.Get("environment_id").(string) != "" {
+		return errors.New("Attribute 'workspace_id' is required for variables with category 'terraform'.")
+	}
+
+	variables := d.Get("variables").(map[string]interface{})
+	sensitiveVariables := d.Get("sensitive_variables").(map[string]interface{})
+	for k := range sensitiveVariables {
+		if _, ok := variables[k]; ok {
+			return fmt.Errorf("variable %q is set in both variables and sensitive_variables", k)
+		}
+	}
+	for _, attr := range []string{"hcl_keys", "final_keys"} {
+		if !d.NewValueKnown(attr) || !d.NewValueKnown("variables") || !d.NewValueKnown("sensitive_variables") {
+			continue
+		}
+		for _, k := range d.Get(attr).(*schema.Set).List() {
+			_, plain := variables[k.(string)]
+			_, sensitive := sensitiveVariables[k.(string)]
+			if !plain && !sensitive {
+				return fmt.Errorf("%s contains %q, which is not a key of variables or sensitive_variables", attr, k)
+			}
+		}
+	}
+
+	if d.HasChange("variables") || d.HasChange("sensitive_variables") {
+		return d.SetNewComputed("variable_ids")
+	}
+	return nil
+}
+
+// listVariableSet returns the variables of the category on the scope
+// of the set, keyed by the variable key.
+func listVariableSet(ctx context.Context, client *scalr.Client, d *schema.ResourceData) (map[string]*scalr.Variable, error) {
+	filter := &scalr.VariableFilter{
+		Category: scalr.String(d.Get("category").(string)),
+	}
+	workspaceID := d.Get("workspace_id").(string)
+	if workspaceID != "" {
+		filter.Workspace = scalr.String(workspaceID)
+	} else {
+		filter.Environment = scalr.String(d.Get("environment_id").(string))
+	}
+
+	variables := make(map[string]*scalr.Variable)
+	options := scalr.VariableListOptions{Filter: filter}
+	for {
+		vl, err := client.Variables.List(ctx, options)
+		if err != nil {
+			return nil, fmt.Errorf("Error retrieving variables: %v", err)
+		}
+
+		for _, v := range vl.Items {
+			// The variables of the workspaces are not on the environment scope.
+			if workspaceID == "" && v.Workspace != nil {
+				continue
+			}
+			variables[v.Key] = v
+		}
+
+		// Exit the loop when we've seen all pages.
+		if vl.CurrentPage >= vl.TotalPages {
+			break
+		}
+
+		// Update the page number to get the next page.
+		options.PageNumber = vl.NextPage
+	}
+	return variables, nil
+}
+
+// applyVariableSet creates, updates and deletes the variables on the scope
+// of the set to match the configuration. Only the variables that differ
+// are written.
+func applyVariableSet(ctx context.Context, client *scalr.Client, d *schema.ResourceData) error {
+	current, err := listVariableSet(ctx, client, d)
+	if err != nil {
+		return err
+	}
+
+	oldVariables, newVariables := d.GetChange("variables")
+	oldSensitive, newSensitive := d.GetChange("sensitive_variables")
+	oldSensitiveValues := oldSensitive.(map[string]interface{})
+	expected := variableSetSpecs(
+		newVariables.(map[string]interface{}),
+		newSensitive.(map[string]interface{}),
+		d.Get("hcl_keys").(*schema.Set),
+		d.Get("final_keys").(*schema.Set),
+	)
+
+	managed := make(map[string]bool)
+	for k := range expected {
+		managed[k] = true
+	}
+	for _, old := range []interface{}{oldVariables, oldSensitive} {
+		for k := range old.(map[string]interface{}) {
+			managed[k] = true
+		}
+	}
+	if d.Get("delete_unmanaged").(bool) {
+		for k := range current {
+			managed[k] = true
+		}
+	}
+
+	keys := make([]string, 0, len(managed))
+	for k := range managed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	category := scalr.CategoryType(d.Get("category").(string))
+	force := &scalr.VariableWriteQueryOptions{Force: scalr.Bool(d.Get("force").(bool))}
+
+	for _, k := range keys {
+		v, exists := current[k]
+		spec, ok := expected[k]
+
+		if exists && (!ok || (v.Sensitive && !spec.sensitive)) {
+			// A sensitive variable cannot be made non-sensitive, it is created again.
+			log.Printf("[DEBUG] Delete variable %s (%s)", k, v.ID)
+			if err := client.Variables.Delete(ctx, v.ID); err != nil && !errors.Is(err, scalr.ErrResourceNotFound) {
+				return fmt.Errorf("Error deleting variable %s: %v", k, err)
+			}
+			exists = false
+		}
+		if !ok {
+			continue
+		}
+
+		if !exists {
+			options := scalr.VariableCreateOptions{
+				Key:          scalr.String(k),
+				Value:        scalr.String(spec.value),
+				Description:  scalr.String(""),
+				Category:     scalr.Category(category),
+				HCL:          scalr.Bool(spec.hcl),
+				Sensitive:    scalr.Bool(spec.sensitive),
+				Final:        scalr.Bool(spec.final),
+				QueryOptions: force,
+				Account:      &scalr.Account{ID: d.Get("account_id").(string)},
+			}
+			if workspaceID := d.Get("workspace_id").(string); workspaceID != "" {
+				options.Workspace = &scalr.Workspace{ID: workspaceID}
+			} else {
+				options.Environment = &scalr.Environment{ID: d.Get("environment_id").(string)}
+			}
+
+			log.Printf("[DEBUG] Create %s variable: %s", category, k)
+			if _, err := client.Variables.Create(ctx, options); err != nil {
+				return fmt.Errorf("Error creating %s variable %s: %v", category, k, err)
+			}
+			continue
+		}
+
+		// The values of sensitive variables cannot be read, so they are
+		// compared with the values in the state.
+		valueChanged := v.Value != spec.value
+		if spec.sensitive {
+			old, known := oldSensitiveValues[k]
+			valueChanged = !v.Sensitive || !known || old.(string) != spec.value
+		}
+		if !valueChanged && v.HCL == spec.hcl && v.Final == spec.final && v.Sensitive == spec.sensitive {
+			continue
+		}
+
+		log.Printf("[DEBUG] Update variable %s (%s)", k, v.ID)
+		_, err := client.Variables.Update(ctx, v.ID, scalr.VariableUpdateOptions{
+			Key:          scalr.String(k),
+			Value:        scalr.String(spec.value),
+			HCL:          scalr.Bool(spec.hcl),
+			Sensitive:    scalr.Bool(spec.sensitive),
+			Final:        scalr.Bool(spec.final),
+			QueryOptions: force,
+		})
+		if err != nil {
+			return fmt.Errorf("Error updating variable %s: %v", k, err)
+		}
+	}
+
+	return nil
+}
+
+// variableSetID returns the ID of the set in the format <scope ID>/<category>.
+func variableSetID(d *schema.ResourceData) string {
+	scopeID := d.Get("workspace_id").(string)
+	if scopeID == "" {
+		scopeID = d.Get("environment_id").(string)
+	}
+	return fmt.Sprintf("%s/%s", scopeID, d.Get("category").(string))
+}
+
+func resourceScalrVariableSetCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	if err := applyVariableSet(ctx, scalrClient, d); err != nil {
+		// The variables written so far are taken over by the next apply.
+		return diag.FromErr(err)
+	}
+
+	d.SetId(variableSetID(d))
+	return resourceScalrVariableSetRead(ctx, d, meta)
+}
+
+func resourceScalrVariableSetRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+	id := d.Id()
+
+	log.Printf("[DEBUG] Read variable set: %s", id)
+	current, err := listVariableSet(ctx, scalrClient, d)
+	if err != nil {
+		return diag.Errorf("Error reading variable set %s: %v", id, err)
+	}
+
+	priorSensitive := d.Get("sensitive_variables").(map[string]interface{})
+	managed := make(map[string]bool)
+	for _, attr := range []string{"variables", "sensitive_variables"} {
+		for k := range d.Get(attr).(map[string]interface{}) {
+			managed[k] = true
+		}
+	}
+
+	variables := make(map[string]interface{})
+	sensitiveVariables := make(map[string]interface{})
+	hclKeys := make([]interface{}, 0)
+	finalKeys := make([]interface{}, 0)
+	ids := make(map[string]interface{})
+	for k, v := range current {
+		// With delete_unmanaged the other variables show up in the plan to be deleted.
+		if !managed[k] && !d.Get("delete_unmanaged").(bool) {
+			continue
+		}
+
+		if v.Sensitive {
+			value, _ := priorSensitive[k].(string)
+			sensitiveVariables[k] = value
+		} else {
+			variables[k] = v.Value
+		}
+		if v.HCL {
+			hclKeys = append(hclKeys, k)
+		}
+		if v.Final {
+			finalKeys = append(finalKeys, k)
+		}
+		ids[k] = v.ID
+		if v.Account != nil {
+			_ = d.Set("account_id", v.Account.ID)
+		}
+	}
+
+	_ = d.Set("variables", variables)
+	_ = d.Set("sensitive_variables", sensitiveVariables)
+	_ = d.Set("hcl_keys", hclKeys)
+	_ = d.Set("final_keys", finalKeys)
+	_ = d.Set("variable_ids", ids)
+
+	return nil
+}
+
+func resourceScalrVariableSetUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	if err := applyVariableSet(ctx, scalrClient, d); err != nil {
+		// Keep the prior state, the next plan shows the remaining changes.
+		d.Partial(true)
+		return diag.FromErr(err)
+	}
+
+	return resourceScalrVariableSetRead(ctx, d, meta)
+}
+
+func resourceScalrVariableSetDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	for k, v := range d.Get("variable_ids").(map[string]interface{}) {
+		log.Printf("[DEBUG] Delete variable %s (%s)", k, v)
+		err := scalrClient.Variables.Delete(ctx, v.(string))
+		if err != nil && !errors.Is(err, scalr.ErrResourceNotFound) {
+			return diag.Errorf("Error deleting variable %s: %v", k, err)
+		}
+	}
+
+	return nil
+}
+
+// resourceScalrVariableSetImport takes over all variables of the category
+// on the scope. The import ID is <workspace or environment ID>/<category>.
+func resourceScalrVariableSetImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	scalrClient := meta.(*scalr.Client)
+
+	parts := strings.Split(d.Id(), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid variable set ID %q, expected <workspace or environment ID>/<category>", d.Id())
+	}
+	scopeID, category := parts[0], parts[1]
+
+	switch {
+	case strings.HasPrefix(scopeID, "ws-"):
+		_ = d.Set("workspace_id", scopeID)
+	case strings.HasPrefix(scopeID, "env-"):
+		_ = d.Set("environment_id", scopeID)
+	default:
+		return nil, fmt.Errorf("invalid variable set ID %q, expected a workspace or an environment ID", d.Id())
+	}
+	_ = d.Set("category", category)
+
+	current, err := listVariableSet(ctx, scalrClient, d)
+	if err != nil {
+		return nil, err
+	}
+	variables := make(map[string]interface{})
+	sensitiveVariables := make(map[string]interface{})
+	for k, v := range current {
+		if v.Sensitive {
+			sensitiveVariables[k] = ""
+		} else {
+			variables[k] = v.Value
+		}
+	}
+	_ = d.Set("variables", variables)
+	_ = d.Set("sensitive_variables", sensitiveVariables)
+
+	return []*schema.ResourceData{d}, nil
+}
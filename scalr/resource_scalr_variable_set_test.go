@@ -0,0 +1,293 @@
+package scalr
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/scalr/go-scalr"
+)
+
+func TestAccScalrVariableSet_basic(t *testing.T) {
+	rInt := GetRandomInteger()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccScalrVariableSetConfig(rInt, `{ region = "us-east-1", zones = "[\"a\", \"b\"]" }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("scalr_variable_set.test", "variables.%", "2"),
+					resource.TestCheckResourceAttr("scalr_variable_set.test", "variables.region", "us-east-1"),
+					resource.TestCheckResourceAttr("scalr_variable_set.test", "sensitive_variables.%", "1"),
+					resource.TestCheckResourceAttr("scalr_variable_set.test", "variable_ids.%", "3"),
+					resource.TestCheckResourceAttrSet("scalr_variable_set.test", "variable_ids.region"),
+				),
+			},
+			{
+				Config: testAccScalrVariableSetConfig(rInt, `{ region = "eu-west-1", zones = "[\"a\"]" }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("scalr_variable_set.test", "variables.region", "eu-west-1"),
+					resource.TestCheckResourceAttr("scalr_variable_set.test", "variables.zones", `["a"]`),
+				),
+			},
+			{
+				ResourceName:            "scalr_variable_set.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"sensitive_variables", "force", "delete_unmanaged"},
+			},
+		},
+	})
+}
+
+func TestAccScalrVariableSet_validation(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource scalr_variable_set test {
+  workspace_id        = "ws-123"
+  category            = "shell"
+  variables           = { token = "public" }
+  sensitive_variables = { token = "secret" }
+}`,
+				ExpectError: regexp.MustCompile("is set in both variables and sensitive_variables"),
+			},
+			{
+				Config: `
+resource scalr_variable_set test {
+  workspace_id = "ws-123"
+  category     = "terraform"
+  variables    = { region = "us-east-1" }
+  hcl_keys     = ["zones"]
+}`,
+				ExpectError: regexp.MustCompile("hcl_keys contains \"zones\", which is not a key"),
+			},
+			{
+				Config: `
+resource scalr_variable_set test {
+  environment_id = "env-123"
+  category       = "terraform"
+}`,
+				ExpectError: regexp.MustCompile("Attribute 'workspace_id' is required"),
+			},
+		},
+	})
+}
+
+func TestVariableSet_delta(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+	transport := &countingTransport{counts: make(map[string]int)}
+	client, err := newScalrClient(&scalr.Config{
+		Address:    server.Address(),
+		Token:      testAPIToken,
+		HTTPClient: &http.Client{Transport: transport},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := client.Environments.Create(ctx, scalr.EnvironmentCreateOptions{
+		Name:    scalr.String("test-env"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating environment: %v", err)
+	}
+	ws, err := client.Workspaces.Create(ctx, scalr.WorkspaceCreateOptions{
+		Name:        scalr.String("test-ws"),
+		Environment: &scalr.Environment{ID: env.ID},
+	})
+	if err != nil {
+		t.Fatalf("error creating workspace: %v", err)
+	}
+	for key, category := range map[string]scalr.CategoryType{"legacy": scalr.CategoryShell, "other": scalr.CategoryTerraform} {
+		_, err := client.Variables.Create(ctx, scalr.VariableCreateOptions{
+			Key:       scalr.String(key),
+			Value:     scalr.String("value"),
+			Category:  scalr.Category(category),
+			Account:   &scalr.Account{ID: defaultAccount},
+			Workspace: &scalr.Workspace{ID: ws.ID},
+		})
+		if err != nil {
+			t.Fatalf("error creating variable: %v", err)
+		}
+	}
+
+	r := resourceScalrVariableSet()
+	config := map[string]interface{}{
+		"workspace_id":        ws.ID,
+		"account_id":          defaultAccount,
+		"category":            string(scalr.CategoryShell),
+		"variables":           map[string]interface{}{"REGION": "us-east-1", "ZONES": `["a"]`},
+		"sensitive_variables": map[string]interface{}{"TOKEN": "s3cr3t"},
+		"hcl_keys":            []interface{}{"ZONES"},
+		"final_keys":          []interface{}{"REGION"},
+	}
+	apply := func(state *terraform.InstanceState) (*terraform.InstanceState, bool) {
+		t.Helper()
+		state, diff, err := testResourcePlan(t, r, state, config, client)
+		if err != nil {
+			t.Fatalf("unexpected plan error: %v", err)
+		}
+		if diff.Empty() {
+			return state, false
+		}
+		newState, diags := r.Apply(ctx, state, diff, client)
+		if diags.HasError() {
+			t.Fatalf("unexpected apply error: %v", diags)
+		}
+		return newState, true
+	}
+	refresh := func(state *terraform.InstanceState) *terraform.InstanceState {
+		t.Helper()
+		state, diags := r.RefreshWithoutUpgrade(ctx, state, client)
+		if diags.HasError() {
+			t.Fatalf("unexpected refresh error: %v", diags)
+		}
+		return state
+	}
+	stored := func() map[string]*testAPIResource {
+		byKey := make(map[string]*testAPIResource)
+		for _, v := range server.resources["vars"] {
+			if v.Attributes["category"] == string(scalr.CategoryShell) {
+				byKey[v.Attributes["key"].(string)] = v
+			}
+		}
+		return byKey
+	}
+
+	state, _ := apply(nil)
+	if state.ID != ws.ID+"/shell" {
+		t.Fatalf("unexpected ID %s", state.ID)
+	}
+	vars := stored()
+	if len(vars) != 4 {
+		t.Fatalf("expected 3 variables next to the unmanaged one, got %d", len(vars))
+	}
+	if v := vars["ZONES"].Attributes; v["value"] != `["a"]` || v["hcl"] != true || v["final"] != false {
+		t.Fatalf("unexpected variable ZONES: %v", v)
+	}
+	if v := vars["REGION"].Attributes; v["final"] != true || v["hcl"] != false {
+		t.Fatalf("unexpected variable REGION: %v", v)
+	}
+	if v := vars["TOKEN"].Attributes; v["value"] != "s3cr3t" || v["sensitive"] != true {
+		t.Fatalf("unexpected variable TOKEN: %v", v)
+	}
+	if n := state.Attributes["variable_ids.%"]; n != "3" {
+		t.Fatalf("expected the IDs of 3 variables, got %s", n)
+	}
+
+	state = refresh(state)
+	if _, changed := apply(state); changed {
+		t.Fatal("expected no changes for the same configuration")
+	}
+
+	// Only the changed variable is written.
+	before := transport.count(http.MethodPatch) + transport.count(http.MethodPost)
+	config["variables"] = map[string]interface{}{"REGION": "eu-west-1", "ZONES": `["a"]`}
+	state, _ = apply(state)
+	if n := transport.count(http.MethodPatch) + transport.count(http.MethodPost) - before; n != 1 {
+		t.Fatalf("expected 1 write, got %d", n)
+	}
+	if v := stored()["REGION"].Attributes["value"]; v != "eu-west-1" {
+		t.Fatalf("expected the new value, got %v", v)
+	}
+
+	// The changes made outside of Terraform are detected on refresh.
+	stored()["ZONES"].Attributes["value"] = `["b"]`
+	state = refresh(state)
+	if v := state.Attributes["variables.ZONES"]; v != `["b"]` {
+		t.Fatalf("expected the drifted value in the state, got %s", v)
+	}
+	state, changed := apply(state)
+	if !changed || stored()["ZONES"].Attributes["value"] != `["a"]` {
+		t.Fatal("expected the drifted value to be restored")
+	}
+
+	// A sensitive variable is created again to make it non-sensitive.
+	tokenID := stored()["TOKEN"].ID
+	config["variables"] = map[string]interface{}{"REGION": "eu-west-1", "ZONES": `["a"]`, "TOKEN": "public"}
+	config["sensitive_variables"] = map[string]interface{}{}
+	state, _ = apply(state)
+	if v := stored()["TOKEN"]; v.ID == tokenID || v.Attributes["sensitive"] != false || v.Attributes["value"] != "public" {
+		t.Fatalf("expected TOKEN to be created again, got %s %v", v.ID, v.Attributes)
+	}
+
+	// The unmanaged variables are only deleted on request.
+	config["delete_unmanaged"] = true
+	state, _ = apply(state)
+	if _, ok := stored()["legacy"]; ok {
+		t.Fatal("expected the unmanaged variable to be deleted")
+	}
+	_, err = client.Variables.Create(ctx, scalr.VariableCreateOptions{
+		Key:       scalr.String("added_in_ui"),
+		Value:     scalr.String("value"),
+		Category:  scalr.Category(scalr.CategoryShell),
+		Account:   &scalr.Account{ID: defaultAccount},
+		Workspace: &scalr.Workspace{ID: ws.ID},
+	})
+	if err != nil {
+		t.Fatalf("error creating variable: %v", err)
+	}
+	state = refresh(state)
+	if v := state.Attributes["variables.added_in_ui"]; v != "value" {
+		t.Fatalf("expected the unmanaged variable to show up in the state, got %q", v)
+	}
+	state, _ = apply(state)
+	if _, ok := stored()["added_in_ui"]; ok {
+		t.Fatal("expected the unmanaged variable to be deleted")
+	}
+
+	if _, diags := r.Apply(ctx, state, &terraform.InstanceDiff{Destroy: true}, client); diags.HasError() {
+		t.Fatalf("unexpected destroy error: %v", diags)
+	}
+	if vars := stored(); len(vars) != 0 {
+		t.Fatalf("expected all variables to be deleted, got %d", len(vars))
+	}
+	if len(server.resources["vars"]) != 1 {
+		t.Fatal("expected the variable of the other category to be kept")
+	}
+}
+
+func TestVariableSet_import(t *testing.T) {
+	client := testScalrClient(t)
+	r := resourceScalrVariableSet()
+
+	for _, id := range []string{"ws-123", "var-123/shell", "ws-123/"} {
+		d := r.Data(&terraform.InstanceState{ID: id})
+		if _, err := r.Importer.StateContext(ctx, d, client); err == nil || !strings.Contains(err.Error(), "invalid variable set ID") {
+			t.Fatalf("expected an error for the import ID %s, got %v", id, err)
+		}
+	}
+}
+
+func testAccScalrVariableSetConfig(rInt int, variables string) string {
+	return fmt.Sprintf(`
+resource scalr_environment test {
+  name       = "test-env-%[1]d"
+  account_id = "%[2]s"
+}
+
+resource scalr_workspace test {
+  name           = "test-ws-%[1]d"
+  environment_id = scalr_environment.test.id
+}
+
+resource scalr_variable_set test {
+  workspace_id        = scalr_workspace.test.id
+  category            = "terraform"
+  variables           = %[3]s
+  sensitive_variables = { password = "s3cr3t" }
+  hcl_keys            = ["zones"]
+}`, rInt, defaultAccount, variables)
+}
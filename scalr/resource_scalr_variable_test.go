@@ -3,6 +3,7 @@ package scalr
 import (
 	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -503,3 +504,108 @@ resource scalr_variable test {
   workspace_id   = scalr_workspace.test.id
 }`, rInt, defaultAccount)
 }
+
+func TestVariable_writeOnlyValue(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+	client, err := newScalrClient(&scalr.Config{Address: server.Address(), Token: testAPIToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := resourceScalrVariable()
+	config := map[string]interface{}{
+		"key":              "secret",
+		"value":            "s3cr3t",
+		"category":         string(scalr.CategoryShell),
+		"sensitive":        true,
+		"value_write_only": true,
+		"account_id":       defaultAccount,
+	}
+	apply := func(state *terraform.InstanceState, config map[string]interface{}) (*terraform.InstanceState, bool) {
+		t.Helper()
+		state, diff, err := testResourcePlan(t, r, state, config, client)
+		if err != nil {
+			t.Fatalf("unexpected plan error: %v", err)
+		}
+		if diff.Empty() {
+			return state, false
+		}
+		newState, diags := r.Apply(ctx, state, diff, client)
+		if diags.HasError() {
+			t.Fatalf("unexpected apply error: %v", diags)
+		}
+		return newState, true
+	}
+	storedValue := func(id string) string {
+		t.Helper()
+		return server.get("vars", id).Attributes["value"].(string)
+	}
+
+	state, _ := apply(nil, config)
+	if v := state.Attributes["value"]; v != "" {
+		t.Fatalf("expected the value not to be stored in the state, got %q", v)
+	}
+	hash := state.Attributes["value_hash"]
+	if !variableValueHashMatches(hash, "s3cr3t") {
+		t.Fatalf("expected the hash of the value in the state, got %q", hash)
+	}
+	if v := storedValue(state.ID); v != "s3cr3t" {
+		t.Fatalf("expected the value to be written, got %q", v)
+	}
+
+	if _, changed := apply(state, config); changed {
+		t.Fatal("expected no changes for the same value")
+	}
+
+	// A rotated value is detected by the hash.
+	config["value"] = "r0tat3d"
+	state, changed := apply(state, config)
+	if !changed {
+		t.Fatal("expected the changed value to be written")
+	}
+	if v := storedValue(state.ID); v != "r0tat3d" {
+		t.Fatalf("expected the value to be written, got %q", v)
+	}
+	if state.Attributes["value_hash"] == hash || !variableValueHashMatches(state.Attributes["value_hash"], "r0tat3d") {
+		t.Fatal("expected the hash of the new value")
+	}
+
+	// A new version rewrites the same value, e.g. after it is changed outside of Terraform.
+	server.get("vars", state.ID).Attributes["value"] = "changed-in-ui"
+	config["value_version"] = 2
+	state, changed = apply(state, config)
+	if !changed {
+		t.Fatal("expected the value to be written for a new version")
+	}
+	if v := storedValue(state.ID); v != "r0tat3d" {
+		t.Fatalf("expected the value to be rewritten, got %q", v)
+	}
+	if _, changed := apply(state, config); changed {
+		t.Fatal("expected no changes for the same version")
+	}
+
+	// Other changes do not overwrite the value with the empty one from the state.
+	config["description"] = "rotated monthly"
+	state, _ = apply(state, config)
+	if v := storedValue(state.ID); v != "r0tat3d" {
+		t.Fatalf("expected the value to be kept, got %q", v)
+	}
+
+	// The value is kept in the state again once the mode is disabled.
+	delete(config, "value_write_only")
+	state, _ = apply(state, config)
+	if v := state.Attributes["value"]; v != "r0tat3d" {
+		t.Fatalf("expected the value in the state, got %q", v)
+	}
+	if v := state.Attributes["value_hash"]; v != "" {
+		t.Fatalf("expected no hash in the state, got %q", v)
+	}
+
+	config["value_write_only"] = true
+	config["sensitive"] = false
+	if _, _, err := testResourcePlan(t, r, state, config, client); err == nil ||
+		!strings.Contains(err.Error(), "only be enabled for sensitive variables") {
+		t.Fatalf("expected an error for a non-sensitive variable, got %v", err)
+	}
+}
@@ -22,6 +22,7 @@ func resourceScalrWorkspace() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: validateWorkspaceVCSPaths,
 
 		SchemaVersion: 4,
 		StateUpgraders: []schema.StateUpgrader{
@@ -74,6 +75,11 @@ func resourceScalrWorkspace() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
+			"require_online_agents": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 
 			"auto_apply": {
 				Type:     schema.TypeBool,
@@ -128,6 +134,12 @@ func resourceScalrWorkspace() *schema.Resource {
 				Default:  "",
 			},
 
+			"validate_vcs_paths": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
 			"hooks": {
 				Type:     schema.TypeList,
 				Optional: true,
@@ -297,19 +309,21 @@ func parseTriggerPrefixDefinitions(vcsRepo map[string]interface{}) ([]string, er
 	return triggerPrefixes, nil
 }
 
-func resourceScalrWorkspaceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	scalrClient := meta.(*scalr.Client)
-
-	// Get the name, environment_id and vcs_provider_id.
-	name := d.Get("name").(string)
-	environmentID := d.Get("environment_id").(string)
+// workspaceConfig is the configuration the workspace options are built from.
+// It is implemented by *schema.ResourceData.
+type workspaceConfig interface {
+	Get(key string) interface{}
+	GetOk(key string) (interface{}, bool)
+}
 
+// newWorkspaceCreateOptions builds the options to create a workspace.
+func newWorkspaceCreateOptions(d workspaceConfig) (*scalr.WorkspaceCreateOptions, error) {
 	// Create a new options struct.
-	options := scalr.WorkspaceCreateOptions{
-		Name:           scalr.String(name),
+	options := &scalr.WorkspaceCreateOptions{
+		Name:           scalr.String(d.Get("name").(string)),
 		AutoApply:      scalr.Bool(d.Get("auto_apply").(bool)),
 		ForceLatestRun: scalr.Bool(d.Get("force_latest_run").(bool)),
-		Environment:    &scalr.Environment{ID: environmentID},
+		Environment:    &scalr.Environment{ID: d.Get("environment_id").(string)},
 		Hooks:          &scalr.HooksOptions{},
 	}
 
@@ -363,7 +377,7 @@ func resourceScalrWorkspaceCreate(ctx context.Context, d *schema.ResourceData, m
 		vcsRepo := v.([]interface{})[0].(map[string]interface{})
 		triggerPrefixes, err := parseTriggerPrefixDefinitions(vcsRepo)
 		if err != nil {
-			return diag.FromErr(err)
+			return nil, err
 		}
 
 		options.VCSRepo = &scalr.WorkspaceVCSRepoOptions{
@@ -412,8 +426,134 @@ func resourceScalrWorkspaceCreate(ctx context.Context, d *schema.ResourceData, m
 		options.Tags = tags
 	}
 
+	return options, nil
+}
+
+// newWorkspaceUpdateOptions builds the options to update a workspace.
+func newWorkspaceUpdateOptions(d workspaceConfig) (*scalr.WorkspaceUpdateOptions, error) {
+	// Create a new options struct.
+	options := &scalr.WorkspaceUpdateOptions{
+		Name:           scalr.String(d.Get("name").(string)),
+		AutoApply:      scalr.Bool(d.Get("auto_apply").(bool)),
+		ForceLatestRun: scalr.Bool(d.Get("force_latest_run").(bool)),
+		Hooks: &scalr.HooksOptions{
+			PreInit:   scalr.String(""),
+			PrePlan:   scalr.String(""),
+			PostPlan:  scalr.String(""),
+			PreApply:  scalr.String(""),
+			PostApply: scalr.String(""),
+		},
+	}
+
+	// Process all configured options.
+	if operations, ok := d.GetOk("operations"); ok {
+		options.Operations = scalr.Bool(operations.(bool))
+	}
+
+	if executionMode, ok := d.GetOk("execution_mode"); ok {
+		options.ExecutionMode = scalr.WorkspaceExecutionModePtr(
+			scalr.WorkspaceExecutionMode(executionMode.(string)),
+		)
+	}
+
+	if autoQueueRunsI, ok := d.GetOk("auto_queue_runs"); ok {
+		options.AutoQueueRuns = scalr.AutoQueueRunsModePtr(
+			scalr.WorkspaceAutoQueueRuns(autoQueueRunsI.(string)),
+		)
+	}
+
+	if tfVersion, ok := d.GetOk("terraform_version"); ok {
+		options.TerraformVersion = scalr.String(tfVersion.(string))
+	}
+
+	if v, ok := d.Get("var_files").([]interface{}); ok {
+		varFiles := make([]string, 0)
+		for _, varFile := range v {
+			varFiles = append(varFiles, varFile.(string))
+		}
+		options.VarFiles = varFiles
+	}
+
+	options.WorkingDirectory = scalr.String(d.Get("working_directory").(string))
+
+	if runOperationTimeout, ok := d.GetOk("run_operation_timeout"); ok {
+		options.RunOperationTimeout = scalr.Int(runOperationTimeout.(int))
+	}
+
+	if vcsProviderId, ok := d.GetOk("vcs_provider_id"); ok {
+		options.VcsProvider = &scalr.VcsProvider{
+			ID: vcsProviderId.(string),
+		}
+	}
+
+	if agentPoolID, ok := d.GetOk("agent_pool_id"); ok {
+		options.AgentPool = &scalr.AgentPool{
+			ID: agentPoolID.(string),
+		}
+	}
+
+	// Get and assert the VCS repo configuration block.
+	if v, ok := d.GetOk("vcs_repo"); ok {
+		vcsRepo := v.([]interface{})[0].(map[string]interface{})
+		triggerPrefixes, err := parseTriggerPrefixDefinitions(vcsRepo)
+		if err != nil {
+			return nil, err
+		}
+
+		options.VCSRepo = &scalr.WorkspaceVCSRepoOptions{
+			Identifier:        scalr.String(vcsRepo["identifier"].(string)),
+			Branch:            scalr.String(vcsRepo["branch"].(string)),
+			Path:              scalr.String(vcsRepo["path"].(string)),
+			TriggerPrefixes:   &triggerPrefixes,
+			DryRunsEnabled:    scalr.Bool(vcsRepo["dry_runs_enabled"].(bool)),
+			IngressSubmodules: scalr.Bool(vcsRepo["ingress_submodules"].(bool)),
+		}
+	}
+
+	// Get and assert the hooks
+	if v, ok := d.GetOk("hooks"); ok {
+		if _, ok := v.([]interface{})[0].(map[string]interface{}); ok {
+			hooks := v.([]interface{})[0].(map[string]interface{})
+
+			options.Hooks = &scalr.HooksOptions{
+				PreInit:   scalr.String(hooks["pre_init"].(string)),
+				PrePlan:   scalr.String(hooks["pre_plan"].(string)),
+				PostPlan:  scalr.String(hooks["post_plan"].(string)),
+				PreApply:  scalr.String(hooks["pre_apply"].(string)),
+				PostApply: scalr.String(hooks["post_apply"].(string)),
+			}
+		}
+	}
+
+	if v, ok := d.GetOk("module_version_id"); ok {
+		options.ModuleVersion = &scalr.ModuleVersion{
+			ID: v.(string),
+		}
+	}
+
+	return options, nil
+}
+
+func resourceScalrWorkspaceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	// Get the name, environment_id and vcs_provider_id.
+	name := d.Get("name").(string)
+	environmentID := d.Get("environment_id").(string)
+
+	options, err := newWorkspaceCreateOptions(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if agentPoolID, ok := d.GetOk("agent_pool_id"); ok && d.Get("require_online_agents").(bool) {
+		if err := checkAgentPoolOnline(ctx, scalrClient, agentPoolID.(string)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	log.Printf("[DEBUG] Create workspace %s for environment: %s", name, environmentID)
-	workspace, err := scalrClient.Workspaces.Create(ctx, options)
+	workspace, err := scalrClient.Workspaces.Create(ctx, *options)
 	if err != nil {
 		return diag.Errorf(
 			"Error creating workspace %s for environment %s: %v", name, environmentID, err)
@@ -559,163 +699,42 @@ func resourceScalrWorkspaceUpdate(ctx context.Context, d *schema.ResourceData, m
 
 	id := d.Id()
 
+	if agentPoolID, ok := d.GetOk("agent_pool_id"); ok && d.HasChange("agent_pool_id") && d.Get("require_online_agents").(bool) {
+		if err := checkAgentPoolOnline(ctx, scalrClient, agentPoolID.(string)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	if d.HasChange("name") || d.HasChange("auto_apply") || d.HasChange("auto_queue_runs") ||
 		d.HasChange("terraform_version") || d.HasChange("working_directory") || d.HasChange("force_latest_run") ||
 		d.HasChange("vcs_repo") || d.HasChange("operations") || d.HasChange("execution_mode") ||
 		d.HasChange("vcs_provider_id") || d.HasChange("agent_pool_id") ||
 		d.HasChange("hooks") || d.HasChange("module_version_id") || d.HasChange("var_files") ||
 		d.HasChange("run_operation_timeout") {
-		// Create a new options struct.
-		options := scalr.WorkspaceUpdateOptions{
-			Name:           scalr.String(d.Get("name").(string)),
-			AutoApply:      scalr.Bool(d.Get("auto_apply").(bool)),
-			ForceLatestRun: scalr.Bool(d.Get("force_latest_run").(bool)),
-			Hooks: &scalr.HooksOptions{
-				PreInit:   scalr.String(""),
-				PrePlan:   scalr.String(""),
-				PostPlan:  scalr.String(""),
-				PreApply:  scalr.String(""),
-				PostApply: scalr.String(""),
-			},
-		}
-
-		// Process all configured options.
-		if operations, ok := d.GetOk("operations"); ok {
-			options.Operations = scalr.Bool(operations.(bool))
-		}
-
-		if executionMode, ok := d.GetOk("execution_mode"); ok {
-			options.ExecutionMode = scalr.WorkspaceExecutionModePtr(
-				scalr.WorkspaceExecutionMode(executionMode.(string)),
-			)
-		}
-
-		if autoQueueRunsI, ok := d.GetOk("auto_queue_runs"); ok {
-			options.AutoQueueRuns = scalr.AutoQueueRunsModePtr(
-				scalr.WorkspaceAutoQueueRuns(autoQueueRunsI.(string)),
-			)
-		}
-
-		if tfVersion, ok := d.GetOk("terraform_version"); ok {
-			options.TerraformVersion = scalr.String(tfVersion.(string))
-		}
-
-		if v, ok := d.Get("var_files").([]interface{}); ok {
-			varFiles := make([]string, 0)
-			for _, varFile := range v {
-				varFiles = append(varFiles, varFile.(string))
-			}
-			options.VarFiles = varFiles
-		}
-
-		options.WorkingDirectory = scalr.String(d.Get("working_directory").(string))
-
-		if runOperationTimeout, ok := d.GetOk("run_operation_timeout"); ok {
-			options.RunOperationTimeout = scalr.Int(runOperationTimeout.(int))
-		}
-
-		if vcsProviderId, ok := d.GetOk("vcs_provider_id"); ok {
-			options.VcsProvider = &scalr.VcsProvider{
-				ID: vcsProviderId.(string),
-			}
-		}
-
-		if agentPoolID, ok := d.GetOk("agent_pool_id"); ok {
-			options.AgentPool = &scalr.AgentPool{
-				ID: agentPoolID.(string),
-			}
-		}
-
-		// Get and assert the VCS repo configuration block.
-		if v, ok := d.GetOk("vcs_repo"); ok {
-			vcsRepo := v.([]interface{})[0].(map[string]interface{})
-			triggerPrefixes, err := parseTriggerPrefixDefinitions(vcsRepo)
-			if err != nil {
-				return diag.FromErr(err)
-			}
-
-			options.VCSRepo = &scalr.WorkspaceVCSRepoOptions{
-				Identifier:        scalr.String(vcsRepo["identifier"].(string)),
-				Branch:            scalr.String(vcsRepo["branch"].(string)),
-				Path:              scalr.String(vcsRepo["path"].(string)),
-				TriggerPrefixes:   &triggerPrefixes,
-				DryRunsEnabled:    scalr.Bool(vcsRepo["dry_runs_enabled"].(bool)),
-				IngressSubmodules: scalr.Bool(vcsRepo["ingress_submodules"].(bool)),
-			}
-		}
-
-		// Get and assert the hooks
-		if v, ok := d.GetOk("hooks"); ok {
-			if _, ok := v.([]interface{})[0].(map[string]interface{}); ok {
-				hooks := v.([]interface{})[0].(map[string]interface{})
-
-				options.Hooks = &scalr.HooksOptions{
-					PreInit:   scalr.String(hooks["pre_init"].(string)),
-					PrePlan:   scalr.String(hooks["pre_plan"].(string)),
-					PostPlan:  scalr.String(hooks["post_plan"].(string)),
-					PreApply:  scalr.String(hooks["pre_apply"].(string)),
-					PostApply: scalr.String(hooks["post_apply"].(string)),
-				}
-			}
-		}
-
-		if v, ok := d.GetOk("module_version_id"); ok {
-			options.ModuleVersion = &scalr.ModuleVersion{
-				ID: v.(string),
-			}
+		options, err := newWorkspaceUpdateOptions(d)
+		if err != nil {
+			return diag.FromErr(err)
 		}
 
 		log.Printf("[DEBUG] Update workspace %s", id)
-		_, err := scalrClient.Workspaces.Update(ctx, id, options)
+		_, err = scalrClient.Workspaces.Update(ctx, id, *options)
 		if err != nil {
 			return diag.Errorf(
 				"Error updating workspace %s: %v", id, err)
 		}
 	}
 	if d.HasChange("provider_configuration") {
-
-		expectedLinks := make(map[string]scalr.ProviderConfigurationLinkCreateOptions)
+		var expectedLinks map[string]scalr.ProviderConfigurationLinkCreateOptions
 		if providerConfigurationI, ok := d.GetOk("provider_configuration"); ok {
-			for _, v := range providerConfigurationI.(*schema.Set).List() {
-				configLink := v.(map[string]interface{})
-				mapID := configLink["id"].(string)
-				linkCreateOption := scalr.ProviderConfigurationLinkCreateOptions{
-					ProviderConfiguration: &scalr.ProviderConfiguration{ID: configLink["id"].(string)},
-				}
-				if v, ok := configLink["alias"]; ok && len(v.(string)) > 0 {
-					linkCreateOption.Alias = scalr.String(v.(string))
-					mapID = mapID + v.(string)
-				}
-				expectedLinks[mapID] = linkCreateOption
-
-			}
+			expectedLinks = expandProviderConfigurationLinks(providerConfigurationI.(*schema.Set).List())
 		}
 
-		currentLinks, err := getProviderConfigurationWorkspaceLinks(ctx, scalrClient, id)
+		_, err := syncProviderConfigurationLinks(
+			ctx, scalrClient, id, expectedLinks, func(*scalr.ProviderConfigurationLink) bool { return true },
+		)
 		if err != nil {
 			return diag.FromErr(err)
 		}
-
-		for _, currentLink := range currentLinks {
-			mapID := currentLink.ProviderConfiguration.ID + currentLink.Alias
-			if _, ok := expectedLinks[mapID]; ok {
-				delete(expectedLinks, mapID)
-			} else {
-				err = scalrClient.ProviderConfigurationLinks.Delete(ctx, currentLink.ID)
-				if err != nil {
-					return diag.Errorf(
-						"Error removing provider configuration link in workspace %s: %v", id, err)
-				}
-			}
-		}
-		for _, createOption := range expectedLinks {
-			_, err = scalrClient.ProviderConfigurationLinks.Create(ctx, id, createOption)
-			if err != nil {
-				return diag.Errorf(
-					"Error creating provider configuration link in workspace %s: %v", id, err)
-			}
-
-		}
 	}
 
 	if d.HasChange("tag_ids") {
@@ -0,0 +1,152 @@
+package scalr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/scalr/go-scalr"
+)
+
+func resourceScalrWorkspaceLock() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceScalrWorkspaceLockCreate,
+		ReadContext:   resourceScalrWorkspaceLockRead,
+		UpdateContext: resourceScalrWorkspaceLockUpdate,
+		DeleteContext: resourceScalrWorkspaceLockDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceScalrWorkspaceLockImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"workspace_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"reason": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"force_unlock": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+		},
+	}
+}
+
+// workspaceAction sends a lock, unlock or force-unlock action to the workspace.
+func workspaceAction(ctx context.Context, scalrClient *scalr.Client, workspaceID, action string, body interface{}) error {
+	var in interface{}
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		in = json.RawMessage(raw)
+	}
+	return doAPIRequest(ctx, scalrClient, "POST", fmt.Sprintf("workspaces/%s/actions/%s", workspaceID, action), in, &scalr.Workspace{})
+}
+
+func resourceScalrWorkspaceLockCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+	workspaceID := d.Get("workspace_id").(string)
+
+	log.Printf("[DEBUG] Lock workspace: %s", workspaceID)
+	body := map[string]string{"reason": d.Get("reason").(string)}
+	if err := workspaceAction(ctx, scalrClient, workspaceID, "lock", body); err != nil {
+		return diag.Errorf("Error locking workspace %s: %v", workspaceID, err)
+	}
+
+	d.SetId(workspaceID)
+
+	return resourceScalrWorkspaceLockRead(ctx, d, meta)
+}
+
+func resourceScalrWorkspaceLockRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+	workspaceID := d.Id()
+
+	log.Printf("[DEBUG] Read lock of workspace: %s", workspaceID)
+	workspace, err := scalrClient.Workspaces.ReadByID(ctx, workspaceID)
+	if err != nil {
+		if errors.Is(err, scalr.ErrResourceNotFound) {
+			log.Printf("[DEBUG] Workspace %s no longer exists", workspaceID)
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("Error retrieving workspace %s: %v", workspaceID, err)
+	}
+
+	// A workspace unlocked outside of Terraform is locked again on the next apply.
+	if !workspace.Locked {
+		log.Printf("[DEBUG] Workspace %s is no longer locked", workspaceID)
+		d.SetId("")
+		return nil
+	}
+
+	_ = d.Set("workspace_id", workspace.ID)
+
+	return nil
+}
+
+func resourceScalrWorkspaceLockUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Only force_unlock can change, and it is used on destroy.
+	return resourceScalrWorkspaceLockRead(ctx, d, meta)
+}
+
+func resourceScalrWorkspaceLockDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+	workspaceID := d.Id()
+
+	log.Printf("[DEBUG] Unlock workspace: %s", workspaceID)
+	err := workspaceAction(ctx, scalrClient, workspaceID, "unlock", nil)
+	if err == nil || errors.Is(err, scalr.ErrResourceNotFound) {
+		return nil
+	}
+
+	// The workspace may have been unlocked in the meantime.
+	workspace, readErr := scalrClient.Workspaces.ReadByID(ctx, workspaceID)
+	if readErr == nil && !workspace.Locked {
+		return nil
+	}
+
+	if !d.Get("force_unlock").(bool) {
+		return diag.Errorf(
+			"Error unlocking workspace %s: %v\n\nSet force_unlock to unlock a workspace locked by another user or a run.",
+			workspaceID, err)
+	}
+
+	log.Printf("[DEBUG] Force unlock workspace: %s", workspaceID)
+	err = workspaceAction(ctx, scalrClient, workspaceID, "force-unlock", nil)
+	if err != nil && !errors.Is(err, scalr.ErrResourceNotFound) {
+		return diag.Errorf("Error force unlocking workspace %s: %v", workspaceID, err)
+	}
+
+	return nil
+}
+
+func resourceScalrWorkspaceLockImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	scalrClient := meta.(*scalr.Client)
+	workspaceID := d.Id()
+
+	workspace, err := scalrClient.Workspaces.ReadByID(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("Error retrieving workspace %s: %v", workspaceID, err)
+	}
+	if !workspace.Locked {
+		return nil, fmt.Errorf("Workspace %s is not locked", workspaceID)
+	}
+
+	_ = d.Set("workspace_id", workspace.ID)
+	_ = d.Set("force_unlock", false)
+
+	return []*schema.ResourceData{d}, nil
+}
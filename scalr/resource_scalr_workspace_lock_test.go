@@ -0,0 +1,198 @@
+package scalr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/scalr/go-scalr"
+)
+
+func TestAccScalrWorkspaceLock_basic(t *testing.T) {
+	rInt := GetRandomInteger()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckScalrWorkspaceLockDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccScalrWorkspaceLockConfig(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckScalrWorkspaceLocked("scalr_workspace_lock.test", true),
+					resource.TestCheckResourceAttrPair(
+						"scalr_workspace_lock.test", "workspace_id", "scalr_workspace.test", "id"),
+					resource.TestCheckResourceAttr("scalr_workspace_lock.test", "reason", "Migration freeze"),
+					resource.TestCheckResourceAttr("scalr_workspace_lock.test", "force_unlock", "false"),
+				),
+			},
+			{
+				ResourceName:      "scalr_workspace_lock.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// The reason is not returned by the API.
+				ImportStateVerifyIgnore: []string{"reason"},
+			},
+		},
+	})
+}
+
+func testAccCheckScalrWorkspaceLocked(n string, locked bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		scalrClient := testAccProvider.Meta().(*scalr.Client)
+
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		ws, err := scalrClient.Workspaces.ReadByID(ctx, rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		if ws.Locked != locked {
+			return fmt.Errorf("Expected workspace %s locked to be %t", ws.ID, locked)
+		}
+		return nil
+	}
+}
+
+func testAccCheckScalrWorkspaceLockDestroy(s *terraform.State) error {
+	scalrClient := testAccProvider.Meta().(*scalr.Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "scalr_workspace_lock" {
+			continue
+		}
+
+		ws, err := scalrClient.Workspaces.ReadByID(ctx, rs.Primary.ID)
+		if err == nil && ws.Locked {
+			return fmt.Errorf("Workspace %s is still locked", rs.Primary.ID)
+		}
+	}
+
+	return nil
+}
+
+func testAccScalrWorkspaceLockConfig(rInt int) string {
+	return fmt.Sprintf(`
+resource scalr_environment test {
+  name       = "test-env-lock-%d"
+  account_id = "%s"
+}
+
+resource scalr_workspace test {
+  name           = "workspace-lock-test"
+  environment_id = scalr_environment.test.id
+}
+
+resource scalr_workspace_lock test {
+  workspace_id = scalr_workspace.test.id
+  reason       = "Migration freeze"
+}`, rInt, defaultAccount)
+}
+
+func TestWorkspaceLock_lifecycle(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+	client, err := newScalrClient(&scalr.Config{Address: server.Address(), Token: testAPIToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := client.Environments.Create(ctx, scalr.EnvironmentCreateOptions{
+		Name:    scalr.String("test-env"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating environment: %v", err)
+	}
+	ws, err := client.Workspaces.Create(ctx, scalr.WorkspaceCreateOptions{
+		Name:        scalr.String("test-ws"),
+		Environment: &scalr.Environment{ID: env.ID},
+	})
+	if err != nil {
+		t.Fatalf("error creating workspace: %v", err)
+	}
+
+	r := resourceScalrWorkspaceLock()
+	lock := func() *schema.ResourceData {
+		d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+			"workspace_id": ws.ID,
+			"reason":       "Migration freeze",
+		})
+		if diags := r.CreateContext(ctx, d, client); diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+		return d
+	}
+	isLocked := func() bool {
+		ws, err := client.Workspaces.ReadByID(ctx, ws.ID)
+		if err != nil {
+			t.Fatalf("error reading workspace: %v", err)
+		}
+		return ws.Locked
+	}
+
+	d := lock()
+	if d.Id() != ws.ID || !isLocked() {
+		t.Fatalf("expected workspace %s to be locked", ws.ID)
+	}
+	if server.get("workspaces", ws.ID).Attributes["lock-reason"] != "Migration freeze" {
+		t.Fatal("expected the reason to be sent")
+	}
+
+	// A second lock of the same workspace fails.
+	d2 := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"workspace_id": ws.ID})
+	if diags := r.CreateContext(ctx, d2, client); !diags.HasError() {
+		t.Fatal("expected error locking a locked workspace")
+	}
+
+	// A manual unlock is detected as drift.
+	if err := workspaceAction(ctx, client, ws.ID, "unlock", nil); err != nil {
+		t.Fatalf("error unlocking workspace: %v", err)
+	}
+	if diags := r.ReadContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatal("expected the lock to be removed from the state")
+	}
+
+	// Destroy releases the lock.
+	d = lock()
+	if diags := r.DeleteContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if isLocked() {
+		t.Fatal("expected workspace to be unlocked")
+	}
+
+	// A lock taken over by someone else is only released with force_unlock.
+	d = lock()
+	server.mu.Lock()
+	server.get("workspaces", ws.ID).Attributes["locked-by"] = "run-123"
+	server.mu.Unlock()
+	diags := r.DeleteContext(ctx, d, client)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "Set force_unlock") {
+		t.Fatalf("expected error unlocking without force_unlock, got %v", diags)
+	}
+	if !isLocked() {
+		t.Fatal("expected workspace to stay locked")
+	}
+	_ = d.Set("force_unlock", true)
+	if diags := r.DeleteContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if isLocked() {
+		t.Fatal("expected workspace to be force unlocked")
+	}
+
+	// Destroying a lock of an unlocked workspace succeeds.
+	if diags := r.DeleteContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+}
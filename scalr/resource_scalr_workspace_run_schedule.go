@@ -8,6 +8,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/scalr/go-scalr"
 	"log"
+	"time"
 )
 
 func resourceScalrWorkspaceRunSchedule() *schema.Resource {
@@ -19,6 +20,7 @@ func resourceScalrWorkspaceRunSchedule() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceScalrWorkspaceRunScheduleImport,
 		},
+		CustomizeDiff: diffWorkspaceRunSchedule,
 
 		Schema: map[string]*schema.Schema{
 			"workspace_id": {
@@ -26,19 +28,123 @@ func resourceScalrWorkspaceRunSchedule() *schema.Resource {
 				Required: true,
 			},
 			"apply_schedule": {
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "",
+				ValidateFunc: validateCronExpression,
 			},
 			"destroy_schedule": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "",
+				ValidateFunc: validateCronExpression,
+			},
+			"next_apply_at": {
 				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "",
+				Computed: true,
+			},
+			"next_destroy_at": {
+				Type:     schema.TypeString,
+				Computed: true,
 			},
 		},
 	}
 }
 
+// runScheduleCheckFrom and runScheduleCheckDays are the period in which
+// the order of the apply and the destroy runs is checked. It covers every
+// combination of the day of month, the month and the day of week.
+var runScheduleCheckFrom = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+const runScheduleCheckDays = 7 * 366
+
+// validateRunScheduleOrder rejects the schedules where, on a day both
+// schedules fire, any destroy run comes before an apply run: either before
+// the first apply run, or followed by an apply run that recreates the
+// destroyed resources.
+func validateRunScheduleOrder(applySchedule, destroySchedule string) error {
+	if applySchedule == "" || destroySchedule == "" {
+		return nil
+	}
+	apply, err := parseCron(applySchedule)
+	if err != nil {
+		return nil
+	}
+	destroy, err := parseCron(destroySchedule)
+	if err != nil {
+		return nil
+	}
+
+	// The times are sorted, so comparing the first destroy run to the
+	// first and the last apply runs covers every pair of runs.
+	applyTimes, destroyTimes := apply.timesOfDay(), destroy.timesOfDay()
+	firstApply, lastApply := applyTimes[0], applyTimes[len(applyTimes)-1]
+	firstDestroy := destroyTimes[0]
+	if firstDestroy >= lastApply {
+		return nil
+	}
+
+	day := runScheduleCheckFrom
+	for i := 0; i < runScheduleCheckDays; i, day = i+1, day.AddDate(0, 0, 1) {
+		if !apply.matchesDay(day) || !destroy.matchesDay(day) {
+			continue
+		}
+		if firstDestroy < firstApply {
+			return fmt.Errorf(
+				"destroy_schedule fires at %s UTC before the first run of apply_schedule at %s UTC on the same day, e.g. on %s",
+				formatTimeOfDay(firstDestroy), formatTimeOfDay(firstApply), day.Format("Monday, 2 January"))
+		}
+		return fmt.Errorf(
+			"apply_schedule fires at %s UTC after the run of destroy_schedule at %s UTC on the same day, e.g. on %s",
+			formatTimeOfDay(lastApply), formatTimeOfDay(firstDestroy), day.Format("Monday, 2 January"))
+	}
+	return nil
+}
+
+// formatTimeOfDay formats minutes since midnight as HH:MM.
+func formatTimeOfDay(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// nextRunAt returns the time of the next run of the schedule in the RFC3339
+// format, or an empty string if there is no schedule.
+func nextRunAt(schedule string, now time.Time) string {
+	if schedule == "" {
+		return ""
+	}
+	c, err := parseCron(schedule)
+	if err != nil {
+		log.Printf("[WARN] Cannot parse run schedule %q: %v", schedule, err)
+		return ""
+	}
+	next, ok := c.next(now)
+	if !ok {
+		return ""
+	}
+	return next.Format(time.RFC3339)
+}
+
+func diffWorkspaceRunSchedule(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if !d.NewValueKnown("apply_schedule") || !d.NewValueKnown("destroy_schedule") {
+		return nil
+	}
+	if err := validateRunScheduleOrder(d.Get("apply_schedule").(string), d.Get("destroy_schedule").(string)); err != nil {
+		return err
+	}
+
+	if d.HasChange("apply_schedule") {
+		if err := d.SetNewComputed("next_apply_at"); err != nil {
+			return err
+		}
+	}
+	if d.HasChange("destroy_schedule") {
+		if err := d.SetNewComputed("next_destroy_at"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func resourceScalrWorkspaceRunScheduleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	scalrClient := meta.(*scalr.Client)
 
@@ -83,6 +189,9 @@ func resourceScalrWorkspaceRunScheduleRead(ctx context.Context, d *schema.Resour
 	// Update the config.
 	_ = d.Set("apply_schedule", workspace.ApplySchedule)
 	_ = d.Set("destroy_schedule", workspace.DestroySchedule)
+	now := time.Now()
+	_ = d.Set("next_apply_at", nextRunAt(workspace.ApplySchedule, now))
+	_ = d.Set("next_destroy_at", nextRunAt(workspace.DestroySchedule, now))
 
 	d.SetId(workspace.ID)
 
@@ -3,7 +3,12 @@ package scalr
 import (
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/scalr/go-scalr"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestScalrWorkspaceRunSchedule_basic(t *testing.T) {
@@ -48,6 +53,103 @@ func TestScalrWorkspaceRunSchedule_default(t *testing.T) {
 	})
 }
 
+func TestScalrWorkspaceRunSchedule_validation(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource scalr_workspace_run_schedule test {
+  workspace_id   = "ws-123"
+  apply_schedule = "0 25 * * *"
+}`,
+				ExpectError: regexp.MustCompile("apply_schedule must be a cron expression: value 25 out of range 0-23 in hour field"),
+			},
+			{
+				Config: `
+resource scalr_workspace_run_schedule test {
+  workspace_id     = "ws-123"
+  apply_schedule   = "0 9 * * 1-5"
+  destroy_schedule = "0 7 * * *"
+}`,
+				ExpectError: regexp.MustCompile("destroy_schedule fires at 07:00 UTC before the first run of apply_schedule at 09:00 UTC"),
+			},
+		},
+	})
+}
+
+func TestValidateRunScheduleOrder(t *testing.T) {
+	for _, tc := range []struct {
+		apply, destroy string
+		err            string
+	}{
+		{"0 8 * * 1-5", "0 20 * * 1-5", ""},
+		{"0 8 * * 1-5", "", ""},
+		{"", "0 20 * * *", ""},
+		// The destroy runs on other days than the apply.
+		{"0 20 * * 1-5", "0 8 * * 6", ""},
+		{"0 20 1 * *", "0 8 2 * *", ""},
+		{"0 9 * * 1-5", "0 7 * * *", "on Monday, 1 January"},
+		{"30 8,20 * * *", "0 8 * * SAT", "destroy_schedule fires at 08:00 UTC before the first run of apply_schedule at 08:30 UTC on the same day, e.g. on Saturday, 6 January"},
+		// Every run of multi-hour schedules is compared.
+		{"0 12 * * *", "0 6,18 * * *", "destroy_schedule fires at 06:00 UTC before the first run of apply_schedule at 12:00 UTC"},
+		{"0 6,18 * * *", "0 12 * * *", "apply_schedule fires at 18:00 UTC after the run of destroy_schedule at 12:00 UTC on the same day, e.g. on Monday, 1 January"},
+		{"*/30 6-8 * * 1-5", "0 9,21 * * 1-5", ""},
+		{"*/30 6-8 * * 1-5", "15 8 * * 1-5", "apply_schedule fires at 08:30 UTC after the run of destroy_schedule at 08:15 UTC"},
+		{"0 6,18 * * 1-5", "0 12 * * 6", ""},
+		// The 29th of February falls on a Thursday in 2024.
+		{"0 9 29 2 *", "0 7 * * THU", "on Thursday, 29 February"},
+	} {
+		err := validateRunScheduleOrder(tc.apply, tc.destroy)
+		if tc.err == "" && err != nil {
+			t.Errorf("%q, %q: unexpected error: %v", tc.apply, tc.destroy, err)
+		}
+		if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("%q, %q: expected error %q, got %v", tc.apply, tc.destroy, tc.err, err)
+		}
+	}
+}
+
+func TestWorkspaceRunSchedule_nextRuns(t *testing.T) {
+	client := testScalrClient(t)
+	env, err := client.Environments.Create(ctx, scalr.EnvironmentCreateOptions{
+		Name:    scalr.String("test-env"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating environment: %v", err)
+	}
+	ws, err := client.Workspaces.Create(ctx, scalr.WorkspaceCreateOptions{
+		Name:        scalr.String("test-ws"),
+		Environment: &scalr.Environment{ID: env.ID},
+	})
+	if err != nil {
+		t.Fatalf("error creating workspace: %v", err)
+	}
+
+	r := resourceScalrWorkspaceRunSchedule()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"workspace_id":   ws.ID,
+		"apply_schedule": "*/5 * * * *",
+	})
+	before := time.Now()
+	if diags := r.CreateContext(ctx, d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	next, err := time.Parse(time.RFC3339, d.Get("next_apply_at").(string))
+	if err != nil {
+		t.Fatalf("unexpected next_apply_at: %v", err)
+	}
+	if !next.After(before) || next.Sub(before) > 5*time.Minute || next.Minute()%5 != 0 {
+		t.Fatalf("unexpected next_apply_at %s", next)
+	}
+	if v := d.Get("next_destroy_at").(string); v != "" {
+		t.Fatalf("expected no next_destroy_at, got %s", v)
+	}
+}
+
 const testScalrWorkspaceRunScheduleCommonConfig = `
 resource scalr_environment test {
   name       = "test-env-rs-%d"
@@ -0,0 +1,808 @@
+package scalr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/scalr/go-scalr"
+)
+
+// Statuses of the workspaces reported by scalr_workspace_set.
+const (
+	workspaceSetStatusCreated   = "created"
+	workspaceSetStatusUpdated   = "updated"
+	workspaceSetStatusUnchanged = "unchanged"
+	workspaceSetStatusFailed    = "failed"
+)
+
+// workspaceSetVariablesChunkSize is the number of workspaces
+// the variables are listed for at once.
+const workspaceSetVariablesChunkSize = 50
+
+func resourceScalrWorkspaceSet() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceScalrWorkspaceSetCreate,
+		ReadContext:   resourceScalrWorkspaceSetRead,
+		UpdateContext: resourceScalrWorkspaceSetUpdate,
+		DeleteContext: resourceScalrWorkspaceSetDelete,
+		CustomizeDiff: resourceScalrWorkspaceSetCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"parallelism": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10,
+				ValidateFunc: validation.IntBetween(1, 50),
+			},
+			"workspace": {
+				Type:     schema.TypeSet,
+				Required: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.NoZeroValues,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"environment_id": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"auto_apply": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"force_latest_run": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"execution_mode": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  string(scalr.WorkspaceExecutionModeRemote),
+							ValidateFunc: validation.StringInSlice(
+								[]string{
+									string(scalr.WorkspaceExecutionModeRemote),
+									string(scalr.WorkspaceExecutionModeLocal),
+								},
+								false,
+							),
+						},
+						"auto_queue_runs": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  string(scalr.AutoQueueRunsModeSkipFirst),
+							ValidateFunc: validation.StringInSlice(
+								[]string{
+									string(scalr.AutoQueueRunsModeSkipFirst),
+									string(scalr.AutoQueueRunsModeAlways),
+									string(scalr.AutoQueueRunsModeNever),
+								},
+								false,
+							),
+						},
+						"terraform_version": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"working_directory": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						"var_files": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validation.NoZeroValues,
+							},
+						},
+						"agent_pool_id": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"tag_ids": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"apply_schedule": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						"destroy_schedule": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						"variables": {
+							Type:     schema.TypeMap,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"workspace_ids": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"statuses": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+// workspaceSpec is an element of the `workspace` blocks of scalr_workspace_set.
+// It implements workspaceConfig, so the workspace options are built
+// the same way as for scalr_workspace.
+type workspaceSpec map[string]interface{}
+
+func (s workspaceSpec) Get(key string) interface{} {
+	return s[key]
+}
+
+func (s workspaceSpec) GetOk(key string) (interface{}, bool) {
+	v, ok := s[key]
+	if !ok || v == nil {
+		return v, false
+	}
+	switch v := v.(type) {
+	case string:
+		return v, v != ""
+	case bool:
+		return v, v
+	case int:
+		return v, v != 0
+	case []interface{}:
+		return v, len(v) > 0
+	case map[string]interface{}:
+		return v, len(v) > 0
+	case *schema.Set:
+		return v, v.Len() > 0
+	}
+	return v, true
+}
+
+func (s workspaceSpec) key() string {
+	return s["key"].(string)
+}
+
+func (s workspaceSpec) variables() map[string]string {
+	variables := make(map[string]string)
+	if v, ok := s["variables"].(map[string]interface{}); ok {
+		for k, value := range v {
+			variables[k] = value.(string)
+		}
+	}
+	return variables
+}
+
+func (s workspaceSpec) tagIDs() *schema.Set {
+	if v, ok := s["tag_ids"].(*schema.Set); ok {
+		return v
+	}
+	return schema.NewSet(schema.HashString, nil)
+}
+
+// workspaceSpecs returns the specs of the `workspace` blocks keyed by their key.
+func workspaceSpecs(v interface{}) map[string]workspaceSpec {
+	specs := make(map[string]workspaceSpec)
+	if set, ok := v.(*schema.Set); ok {
+		for _, item := range set.List() {
+			spec := workspaceSpec(item.(map[string]interface{}))
+			// Elements removed from the set may show up zeroed in the diff.
+			if spec.key() == "" {
+				continue
+			}
+			specs[spec.key()] = spec
+		}
+	}
+	return specs
+}
+
+// forEachParallel calls fn for each of the keys, running at most
+// parallelism calls at once.
+func forEachParallel(keys []string, parallelism int, fn func(key string)) {
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for _, key := range keys {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(key)
+		}(key)
+	}
+	wg.Wait()
+}
+
+func resourceScalrWorkspaceSetCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	seen := make(map[string]bool)
+	for _, item := range d.Get("workspace").(*schema.Set).List() {
+		key := item.(map[string]interface{})["key"].(string)
+		if key == "" {
+			// The key is not known yet.
+			continue
+		}
+		if seen[key] {
+			return fmt.Errorf("duplicate workspace key: %s", key)
+		}
+		seen[key] = true
+	}
+
+	if !d.HasChange("workspace") {
+		return nil
+	}
+
+	oldSpecs, newSpecs := d.GetChange("workspace")
+	oldByKey := workspaceSpecs(oldSpecs)
+	newIDs := false
+	for key, spec := range workspaceSpecs(newSpecs) {
+		old, ok := oldByKey[key]
+		if !ok || old["environment_id"] != spec["environment_id"] {
+			newIDs = true
+		}
+	}
+	if newIDs || len(oldByKey) != len(workspaceSpecs(newSpecs)) {
+		if err := d.SetNewComputed("workspace_ids"); err != nil {
+			return err
+		}
+	}
+	return d.SetNewComputed("statuses")
+}
+
+// workspaceSetApplier applies the changes of the workspace specs
+// with bounded parallelism.
+type workspaceSetApplier struct {
+	client      *scalr.Client
+	parallelism int
+
+	mu       sync.Mutex
+	ids      map[string]string
+	statuses map[string]string
+	applied  map[string]workspaceSpec
+	diags    diag.Diagnostics
+	accounts map[string]string
+}
+
+func newWorkspaceSetApplier(d *schema.ResourceData, client *scalr.Client, applied map[string]workspaceSpec) *workspaceSetApplier {
+	a := &workspaceSetApplier{
+		client:      client,
+		parallelism: d.Get("parallelism").(int),
+		ids:         make(map[string]string),
+		statuses:    make(map[string]string),
+		applied:     applied,
+		accounts:    make(map[string]string),
+	}
+	// The IDs are marked as computed on plan, so read the prior ones.
+	ids, _ := d.GetChange("workspace_ids")
+	for k, v := range ids.(map[string]interface{}) {
+		a.ids[k] = v.(string)
+	}
+	return a
+}
+
+func (a *workspaceSetApplier) fail(key string, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.statuses[key] = workspaceSetStatusFailed
+	a.diags = append(a.diags, diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("Error applying workspace %q", key),
+		Detail:   err.Error(),
+	})
+}
+
+func (a *workspaceSetApplier) done(key, id, status string, spec workspaceSpec) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if spec == nil {
+		delete(a.ids, key)
+		delete(a.applied, key)
+		delete(a.statuses, key)
+		return
+	}
+	a.statuses[key] = status
+	a.ids[key] = id
+	a.applied[key] = spec
+}
+
+// accountID returns the account of the environment, which is needed
+// to create the variables.
+func (a *workspaceSetApplier) accountID(ctx context.Context, environmentID string) (string, error) {
+	a.mu.Lock()
+	accountID, ok := a.accounts[environmentID]
+	a.mu.Unlock()
+	if ok {
+		return accountID, nil
+	}
+
+	environment, err := a.client.Environments.Read(ctx, environmentID)
+	if err != nil {
+		return "", fmt.Errorf("Error retrieving environment %s: %v", environmentID, err)
+	}
+	if environment.Account == nil {
+		return "", fmt.Errorf("Environment %s has no account", environmentID)
+	}
+
+	a.mu.Lock()
+	a.accounts[environmentID] = environment.Account.ID
+	a.mu.Unlock()
+	return environment.Account.ID, nil
+}
+
+func (a *workspaceSetApplier) create(ctx context.Context, spec workspaceSpec) error {
+	options, err := newWorkspaceCreateOptions(spec)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Create workspace %s for environment: %s", *options.Name, options.Environment.ID)
+	workspace, err := a.client.Workspaces.Create(ctx, *options)
+	if err != nil {
+		return fmt.Errorf(
+			"Error creating workspace %s for environment %s: %v", *options.Name, options.Environment.ID, err)
+	}
+	// Remember the workspace right away, so it is not lost on further errors.
+	a.done(spec.key(), workspace.ID, workspaceSetStatusFailed, workspaceSpec{
+		"key": spec.key(), "name": spec["name"], "environment_id": spec["environment_id"],
+	})
+
+	if err := a.setSchedule(ctx, workspace.ID, nil, spec); err != nil {
+		return err
+	}
+	if err := a.syncVariables(ctx, workspace.ID, nil, spec); err != nil {
+		return err
+	}
+
+	a.done(spec.key(), workspace.ID, workspaceSetStatusCreated, spec)
+	return nil
+}
+
+func (a *workspaceSetApplier) update(ctx context.Context, id string, old, spec workspaceSpec) error {
+	options, err := newWorkspaceUpdateOptions(spec)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Update workspace %s", id)
+	_, err = a.client.Workspaces.Update(ctx, id, *options)
+	if err != nil {
+		return fmt.Errorf("Error updating workspace %s: %v", id, err)
+	}
+
+	oldTags, newTags := old.tagIDs(), spec.tagIDs()
+	if tagsToAdd := InterfaceArrToTagRelationArr(newTags.Difference(oldTags).List()); len(tagsToAdd) > 0 {
+		if err := a.client.WorkspaceTags.Add(ctx, id, tagsToAdd); err != nil {
+			return fmt.Errorf("Error adding tags to workspace %s: %v", id, err)
+		}
+	}
+	if tagsToDelete := InterfaceArrToTagRelationArr(oldTags.Difference(newTags).List()); len(tagsToDelete) > 0 {
+		if err := a.client.WorkspaceTags.Delete(ctx, id, tagsToDelete); err != nil {
+			return fmt.Errorf("Error deleting tags from workspace %s: %v", id, err)
+		}
+	}
+
+	if err := a.setSchedule(ctx, id, old, spec); err != nil {
+		return err
+	}
+	if err := a.syncVariables(ctx, id, old, spec); err != nil {
+		return err
+	}
+
+	a.done(spec.key(), id, workspaceSetStatusUpdated, spec)
+	return nil
+}
+
+func (a *workspaceSetApplier) delete(ctx context.Context, key, id string) error {
+	log.Printf("[DEBUG] Delete workspace %s", id)
+	err := a.client.Workspaces.Delete(ctx, id)
+	if err != nil && !errors.Is(err, scalr.ErrResourceNotFound) {
+		return fmt.Errorf("Error deleting workspace %s: %v", id, err)
+	}
+	a.done(key, "", "", nil)
+	return nil
+}
+
+func (a *workspaceSetApplier) setSchedule(ctx context.Context, id string, old, spec workspaceSpec) error {
+	applySchedule := spec["apply_schedule"].(string)
+	destroySchedule := spec["destroy_schedule"].(string)
+	if old == nil && applySchedule == "" && destroySchedule == "" {
+		return nil
+	}
+	if old != nil && old["apply_schedule"] == applySchedule && old["destroy_schedule"] == destroySchedule {
+		return nil
+	}
+
+	log.Printf("[DEBUG] Set run schedule of workspace %s", id)
+	_, err := a.client.Workspaces.SetSchedule(ctx, id, scalr.WorkspaceRunScheduleOptions{
+		ApplySchedule:   applySchedule,
+		DestroySchedule: destroySchedule,
+	})
+	if err != nil {
+		return fmt.Errorf("Error setting run schedule of workspace %s: %v", id, err)
+	}
+	return nil
+}
+
+// syncVariables creates, updates and deletes the Terraform variables
+// of the workspace that are managed by the spec.
+func (a *workspaceSetApplier) syncVariables(ctx context.Context, id string, old, spec workspaceSpec) error {
+	expected := spec.variables()
+	managed := make(map[string]bool)
+	for k := range expected {
+		managed[k] = true
+	}
+	if old != nil {
+		for k := range old.variables() {
+			managed[k] = true
+		}
+	}
+	if len(managed) == 0 {
+		return nil
+	}
+
+	current := make(map[string]*scalr.Variable)
+	if old != nil {
+		variables, err := listWorkspaceSetVariables(ctx, a.client, []string{id})
+		if err != nil {
+			return err
+		}
+		current = variables[id]
+	}
+
+	for k := range managed {
+		v, exists := current[k]
+		value, ok := expected[k]
+		switch {
+		case !ok && exists:
+			if err := a.client.Variables.Delete(ctx, v.ID); err != nil {
+				return fmt.Errorf("Error deleting variable %s of workspace %s: %v", k, id, err)
+			}
+		case ok && exists && v.Value != value:
+			_, err := a.client.Variables.Update(ctx, v.ID, scalr.VariableUpdateOptions{Value: scalr.String(value)})
+			if err != nil {
+				return fmt.Errorf("Error updating variable %s of workspace %s: %v", k, id, err)
+			}
+		case ok && !exists:
+			accountID, err := a.accountID(ctx, spec["environment_id"].(string))
+			if err != nil {
+				return err
+			}
+			_, err = a.client.Variables.Create(ctx, scalr.VariableCreateOptions{
+				Key:       scalr.String(k),
+				Value:     scalr.String(value),
+				Category:  scalr.Category(scalr.CategoryTerraform),
+				Account:   &scalr.Account{ID: accountID},
+				Workspace: &scalr.Workspace{ID: id},
+			})
+			if err != nil {
+				return fmt.Errorf("Error creating variable %s of workspace %s: %v", k, id, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// listWorkspaceSetVariables returns the Terraform variables of the workspaces
+// keyed by the workspace ID and the variable key.
+func listWorkspaceSetVariables(
+	ctx context.Context, client *scalr.Client, workspaceIDs []string,
+) (map[string]map[string]*scalr.Variable, error) {
+	variables := make(map[string]map[string]*scalr.Variable)
+	for start := 0; start < len(workspaceIDs); start += workspaceSetVariablesChunkSize {
+		end := start + workspaceSetVariablesChunkSize
+		if end > len(workspaceIDs) {
+			end = len(workspaceIDs)
+		}
+
+		options := scalr.VariableListOptions{
+			Filter: &scalr.VariableFilter{
+				Workspace: scalr.String("in:" + strings.Join(workspaceIDs[start:end], ",")),
+				Category:  scalr.String(string(scalr.CategoryTerraform)),
+			},
+		}
+		for {
+			vl, err := client.Variables.List(ctx, options)
+			if err != nil {
+				return nil, fmt.Errorf("Error retrieving variables: %v", err)
+			}
+
+			for _, v := range vl.Items {
+				if v.Workspace == nil {
+					continue
+				}
+				if variables[v.Workspace.ID] == nil {
+					variables[v.Workspace.ID] = make(map[string]*scalr.Variable)
+				}
+				variables[v.Workspace.ID][v.Key] = v
+			}
+
+			// Exit the loop when we've seen all pages.
+			if vl.CurrentPage >= vl.TotalPages {
+				break
+			}
+
+			// Update the page number to get the next page.
+			options.PageNumber = vl.NextPage
+		}
+	}
+	return variables, nil
+}
+
+// apply deletes, creates and updates the workspaces to turn the old specs
+// into the new ones.
+func (a *workspaceSetApplier) apply(ctx context.Context, oldSpecs, newSpecs map[string]workspaceSpec, hash func(interface{}) int) {
+	var toDelete, toCreate, toUpdate []string
+	for key, old := range oldSpecs {
+		spec, ok := newSpecs[key]
+		if _, exists := a.ids[key]; exists && (!ok || spec["environment_id"] != old["environment_id"]) {
+			toDelete = append(toDelete, key)
+		}
+	}
+	for key, spec := range newSpecs {
+		old, ok := oldSpecs[key]
+		_, exists := a.ids[key]
+		switch {
+		case !ok || !exists || spec["environment_id"] != old["environment_id"]:
+			toCreate = append(toCreate, key)
+		case hash(map[string]interface{}(old)) != hash(map[string]interface{}(spec)):
+			toUpdate = append(toUpdate, key)
+		default:
+			a.statuses[key] = workspaceSetStatusUnchanged
+		}
+	}
+	sort.Strings(toDelete)
+	sort.Strings(toCreate)
+	sort.Strings(toUpdate)
+
+	deleteIDs := make(map[string]string, len(toDelete))
+	for _, key := range toDelete {
+		deleteIDs[key] = a.ids[key]
+	}
+	forEachParallel(toDelete, a.parallelism, func(key string) {
+		if err := a.delete(ctx, key, deleteIDs[key]); err != nil {
+			a.fail(key, err)
+		}
+	})
+	forEachParallel(toCreate, a.parallelism, func(key string) {
+		if err := a.create(ctx, newSpecs[key]); err != nil {
+			a.fail(key, err)
+		}
+	})
+	updateIDs := make(map[string]string, len(toUpdate))
+	for _, key := range toUpdate {
+		updateIDs[key] = a.ids[key]
+	}
+	forEachParallel(toUpdate, a.parallelism, func(key string) {
+		if err := a.update(ctx, updateIDs[key], oldSpecs[key], newSpecs[key]); err != nil {
+			a.fail(key, err)
+		}
+	})
+}
+
+// save stores the applied specs, the IDs and the statuses of the workspaces.
+func (a *workspaceSetApplier) save(d *schema.ResourceData) {
+	applied := make([]interface{}, 0, len(a.applied))
+	for _, spec := range a.applied {
+		applied = append(applied, map[string]interface{}(spec))
+	}
+	_ = d.Set("workspace", applied)
+	_ = d.Set("workspace_ids", a.ids)
+	_ = d.Set("statuses", a.statuses)
+}
+
+func resourceScalrWorkspaceSetCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	specs := d.Get("workspace").(*schema.Set)
+	a := newWorkspaceSetApplier(d, scalrClient, make(map[string]workspaceSpec))
+	a.apply(ctx, nil, workspaceSpecs(specs), specs.F)
+
+	if a.diags.HasError() {
+		// Roll back the created workspaces, as the state of a failed
+		// create is not kept.
+		keys := make([]string, 0, len(a.ids))
+		ids := make(map[string]string, len(a.ids))
+		for key, id := range a.ids {
+			keys = append(keys, key)
+			ids[key] = id
+		}
+		forEachParallel(keys, a.parallelism, func(key string) {
+			if err := a.delete(ctx, key, ids[key]); err != nil {
+				a.fail(key, err)
+			}
+		})
+		return a.diags
+	}
+
+	d.SetId(resource.UniqueId())
+	a.save(d)
+	return resourceScalrWorkspaceSetRead(ctx, d, meta)
+}
+
+func resourceScalrWorkspaceSetRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	ids := make(map[string]string)
+	for k, v := range d.Get("workspace_ids").(map[string]interface{}) {
+		ids[k] = v.(string)
+	}
+	statuses := d.Get("statuses").(map[string]interface{})
+	specs := workspaceSpecs(d.Get("workspace"))
+
+	// Read the workspaces with one listing per environment.
+	wanted := make(map[string]map[string]bool)
+	for key, spec := range specs {
+		envID := spec["environment_id"].(string)
+		if wanted[envID] == nil {
+			wanted[envID] = make(map[string]bool)
+		}
+		wanted[envID][ids[key]] = true
+	}
+	workspaces := make(map[string]*scalr.Workspace)
+	for envID, wantedIDs := range wanted {
+		log.Printf("[DEBUG] Read workspaces of environment: %s", envID)
+		items, err := listWorkspaces(ctx, scalrClient, envID, func(w *scalr.Workspace) bool {
+			return wantedIDs[w.ID]
+		})
+		if err != nil {
+			return diag.Errorf("Error retrieving workspaces of environment %s: %v", envID, err)
+		}
+		for _, w := range items {
+			workspaces[w.ID] = w
+		}
+	}
+
+	variablesWorkspaceIDs := make([]string, 0)
+	for key, spec := range specs {
+		if _, ok := workspaces[ids[key]]; ok && len(spec.variables()) > 0 {
+			variablesWorkspaceIDs = append(variablesWorkspaceIDs, ids[key])
+		}
+	}
+	sort.Strings(variablesWorkspaceIDs)
+	variables, err := listWorkspaceSetVariables(ctx, scalrClient, variablesWorkspaceIDs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	current := make([]interface{}, 0, len(specs))
+	for key, spec := range specs {
+		w, ok := workspaces[ids[key]]
+		if !ok {
+			log.Printf("[DEBUG] Workspace %s (%s) not found", key, ids[key])
+			delete(ids, key)
+			delete(statuses, key)
+			continue
+		}
+		current = append(current, flattenWorkspaceSpec(key, spec, w, variables[w.ID]))
+	}
+
+	// Drop the statuses of the workspaces that are no longer in the set.
+	for key := range statuses {
+		if _, ok := ids[key]; !ok {
+			delete(statuses, key)
+		}
+	}
+
+	_ = d.Set("workspace", current)
+	_ = d.Set("workspace_ids", ids)
+	_ = d.Set("statuses", statuses)
+
+	return nil
+}
+
+// flattenWorkspaceSpec returns the spec of the workspace as it is
+// in Scalr. Only the attributes set in the prior spec are reported
+// for the optional attributes the server computes by itself.
+func flattenWorkspaceSpec(key string, prior workspaceSpec, w *scalr.Workspace, variables map[string]*scalr.Variable) map[string]interface{} {
+	spec := map[string]interface{}{
+		"key":               key,
+		"name":              w.Name,
+		"environment_id":    prior["environment_id"],
+		"auto_apply":        w.AutoApply,
+		"force_latest_run":  w.ForceLatestRun,
+		"execution_mode":    string(w.ExecutionMode),
+		"auto_queue_runs":   string(w.AutoQueueRuns),
+		"terraform_version": "",
+		"working_directory": w.WorkingDirectory,
+		"var_files":         w.VarFiles,
+		"agent_pool_id":     "",
+		"apply_schedule":    w.ApplySchedule,
+		"destroy_schedule":  w.DestroySchedule,
+	}
+	if w.Environment != nil {
+		spec["environment_id"] = w.Environment.ID
+	}
+	if prior["terraform_version"] != "" {
+		spec["terraform_version"] = w.TerraformVersion
+	}
+	if w.AgentPool != nil {
+		spec["agent_pool_id"] = w.AgentPool.ID
+	}
+
+	tagIDs := make([]interface{}, 0, len(w.Tags))
+	for _, tag := range w.Tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	spec["tag_ids"] = schema.NewSet(schema.HashString, tagIDs)
+
+	values := make(map[string]interface{})
+	for k := range prior.variables() {
+		if v, ok := variables[k]; ok {
+			values[k] = v.Value
+		}
+	}
+	spec["variables"] = values
+
+	return spec
+}
+
+func resourceScalrWorkspaceSetUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	oldSpecs, newSpecs := d.GetChange("workspace")
+	applied := workspaceSpecs(oldSpecs)
+	a := newWorkspaceSetApplier(d, scalrClient, applied)
+	a.apply(ctx, workspaceSpecs(oldSpecs), workspaceSpecs(newSpecs), newSpecs.(*schema.Set).F)
+
+	// Keep the progress in the state even if some workspaces failed.
+	a.save(d)
+	if a.diags.HasError() {
+		return a.diags
+	}
+
+	return resourceScalrWorkspaceSetRead(ctx, d, meta)
+}
+
+func resourceScalrWorkspaceSetDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scalrClient := meta.(*scalr.Client)
+
+	a := newWorkspaceSetApplier(d, scalrClient, workspaceSpecs(d.Get("workspace")))
+	keys := make([]string, 0, len(a.ids))
+	ids := make(map[string]string, len(a.ids))
+	for key, id := range a.ids {
+		keys = append(keys, key)
+		ids[key] = id
+	}
+	sort.Strings(keys)
+
+	forEachParallel(keys, a.parallelism, func(key string) {
+		if err := a.delete(ctx, key, ids[key]); err != nil {
+			a.fail(key, err)
+		}
+	})
+
+	if a.diags.HasError() {
+		// Keep the workspaces that failed to be deleted in the state.
+		a.save(d)
+		return a.diags
+	}
+	return nil
+}
@@ -0,0 +1,353 @@
+package scalr
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/scalr/go-scalr"
+)
+
+func TestAccScalrWorkspaceSet_basic(t *testing.T) {
+	rInt := GetRandomInteger()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckScalrWorkspaceSetDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccScalrWorkspaceSetConfig(rInt, `
+  workspace {
+    key            = "app"
+    name           = "app-${local.suffix}"
+    environment_id = scalr_environment.test.id
+    auto_apply     = true
+    variables = {
+      region = "us-east-1"
+    }
+  }
+  workspace {
+    key            = "db"
+    name           = "db-${local.suffix}"
+    environment_id = scalr_environment.test.id
+    apply_schedule = "0 22 * * 1-5"
+  }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("scalr_workspace_set.test", "workspace.#", "2"),
+					resource.TestCheckResourceAttrSet("scalr_workspace_set.test", "workspace_ids.app"),
+					resource.TestCheckResourceAttrSet("scalr_workspace_set.test", "workspace_ids.db"),
+					resource.TestCheckResourceAttr("scalr_workspace_set.test", "statuses.app", workspaceSetStatusCreated),
+					resource.TestCheckResourceAttr("scalr_workspace_set.test", "statuses.db", workspaceSetStatusCreated),
+				),
+			},
+			{
+				Config: testAccScalrWorkspaceSetConfig(rInt, `
+  workspace {
+    key            = "app"
+    name           = "app-${local.suffix}"
+    environment_id = scalr_environment.test.id
+    auto_apply     = false
+    variables = {
+      region = "eu-west-1"
+    }
+  }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("scalr_workspace_set.test", "workspace.#", "1"),
+					resource.TestCheckResourceAttr("scalr_workspace_set.test", "workspace_ids.%", "1"),
+					resource.TestCheckResourceAttr("scalr_workspace_set.test", "statuses.app", workspaceSetStatusUpdated),
+				),
+			},
+		},
+	})
+}
+
+func TestWorkspaceSet_lifecycle(t *testing.T) {
+	client := testScalrClient(t)
+
+	env, err := client.Environments.Create(ctx, scalr.EnvironmentCreateOptions{
+		Name:    scalr.String("test-env"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating environment: %v", err)
+	}
+
+	r := resourceScalrWorkspaceSet()
+	apply := func(t *testing.T, state *terraform.InstanceState, raw map[string]interface{}) *terraform.InstanceState {
+		diff, err := r.Diff(ctx, state, terraform.NewResourceConfigRaw(raw), client)
+		if err != nil {
+			t.Fatalf("error planning: %v", err)
+		}
+		if diff == nil {
+			return state
+		}
+		newState, diags := r.Apply(ctx, state, diff, client)
+		if diags.HasError() {
+			t.Fatalf("error applying: %v", diags)
+		}
+		return newState
+	}
+	spec := func(key string, extra map[string]interface{}) map[string]interface{} {
+		s := map[string]interface{}{
+			"key":            key,
+			"name":           "ws-" + key,
+			"environment_id": env.ID,
+		}
+		for k, v := range extra {
+			s[k] = v
+		}
+		return s
+	}
+
+	workspaces := make([]interface{}, 0)
+	for i := 0; i < 5; i++ {
+		workspaces = append(workspaces, spec(fmt.Sprintf("w%d", i), nil))
+	}
+	workspaces = append(workspaces, spec("vars", map[string]interface{}{
+		"auto_apply":     true,
+		"apply_schedule": "0 22 * * 1-5",
+		"variables":      map[string]interface{}{"region": "us-east-1", "size": "small"},
+	}))
+
+	state := apply(t, nil, map[string]interface{}{
+		"parallelism": 2,
+		"workspace":   workspaces,
+	})
+	if n := state.Attributes["workspace_ids.%"]; n != "6" {
+		t.Fatalf("expected 6 workspaces, got %s", n)
+	}
+	if s := state.Attributes["statuses.vars"]; s != workspaceSetStatusCreated {
+		t.Fatalf("expected status %q, got %q", workspaceSetStatusCreated, s)
+	}
+
+	varsID := state.Attributes["workspace_ids.vars"]
+	ws, err := client.Workspaces.ReadByID(ctx, varsID)
+	if err != nil {
+		t.Fatalf("error reading workspace: %v", err)
+	}
+	if !ws.AutoApply || ws.ApplySchedule != "0 22 * * 1-5" {
+		t.Fatalf("unexpected workspace settings: auto_apply=%t apply_schedule=%q", ws.AutoApply, ws.ApplySchedule)
+	}
+	variables, err := listWorkspaceSetVariables(ctx, client, []string{varsID})
+	if err != nil {
+		t.Fatalf("error listing variables: %v", err)
+	}
+	if len(variables[varsID]) != 2 || variables[varsID]["region"].Value != "us-east-1" {
+		t.Fatalf("unexpected variables: %v", variables[varsID])
+	}
+
+	// Nothing to change after a refresh.
+	state, diags := r.RefreshWithoutUpgrade(ctx, state, client)
+	if diags.HasError() {
+		t.Fatalf("error refreshing: %v", diags)
+	}
+	raw := map[string]interface{}{"parallelism": 2, "workspace": workspaces}
+	diff, err := r.Diff(ctx, state, terraform.NewResourceConfigRaw(raw), client)
+	if err != nil {
+		t.Fatalf("error planning: %v", err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected no changes, got %v", diff)
+	}
+
+	// Change one workspace, remove another and add a new one.
+	workspaces[len(workspaces)-1] = spec("vars", map[string]interface{}{
+		"auto_apply": false,
+		"variables":  map[string]interface{}{"region": "eu-west-1"},
+	})
+	workspaces[0] = spec("new", nil)
+	w0 := state.Attributes["workspace_ids.w0"]
+	state = apply(t, state, map[string]interface{}{"parallelism": 2, "workspace": workspaces})
+
+	if _, ok := state.Attributes["workspace_ids.w0"]; ok {
+		t.Fatal("expected workspace w0 to be removed")
+	}
+	if _, ok := state.Attributes["statuses.w0"]; ok {
+		t.Fatal("expected the status of workspace w0 to be removed")
+	}
+	if _, err := client.Workspaces.ReadByID(ctx, w0); err == nil {
+		t.Fatalf("expected workspace %s to be deleted", w0)
+	}
+	for key, status := range map[string]string{
+		"new":  workspaceSetStatusCreated,
+		"vars": workspaceSetStatusUpdated,
+		"w1":   workspaceSetStatusUnchanged,
+	} {
+		if s := state.Attributes["statuses."+key]; s != status {
+			t.Fatalf("expected status of %s to be %q, got %q", key, status, s)
+		}
+	}
+	if state.Attributes["workspace_ids.vars"] != varsID {
+		t.Fatal("expected the updated workspace to keep its ID")
+	}
+	ws, err = client.Workspaces.ReadByID(ctx, varsID)
+	if err != nil {
+		t.Fatalf("error reading workspace: %v", err)
+	}
+	if ws.AutoApply || ws.ApplySchedule != "" {
+		t.Fatalf("unexpected workspace settings: auto_apply=%t apply_schedule=%q", ws.AutoApply, ws.ApplySchedule)
+	}
+	variables, err = listWorkspaceSetVariables(ctx, client, []string{varsID})
+	if err != nil {
+		t.Fatalf("error listing variables: %v", err)
+	}
+	if len(variables[varsID]) != 1 || variables[varsID]["region"].Value != "eu-west-1" {
+		t.Fatalf("unexpected variables: %v", variables[varsID])
+	}
+
+	// A refresh drops the statuses of the workspaces that are not in the set.
+	stale := state.DeepCopy()
+	stale.Attributes["statuses.stale"] = workspaceSetStatusUnchanged
+	stale.Attributes["statuses.%"] = "7"
+	stale, diags = r.RefreshWithoutUpgrade(ctx, stale, client)
+	if diags.HasError() {
+		t.Fatalf("error refreshing: %v", diags)
+	}
+	if _, ok := stale.Attributes["statuses.stale"]; ok || stale.Attributes["statuses.%"] != "6" {
+		t.Fatalf("expected the stale status to be dropped, got %v", stale.Attributes)
+	}
+
+	// A workspace deleted outside of Terraform is created again.
+	if err := client.Workspaces.Delete(ctx, state.Attributes["workspace_ids.w1"]); err != nil {
+		t.Fatalf("error deleting workspace: %v", err)
+	}
+	state, diags = r.RefreshWithoutUpgrade(ctx, state, client)
+	if diags.HasError() {
+		t.Fatalf("error refreshing: %v", diags)
+	}
+	state = apply(t, state, map[string]interface{}{"parallelism": 2, "workspace": workspaces})
+	if s := state.Attributes["statuses.w1"]; s != workspaceSetStatusCreated {
+		t.Fatalf("expected the missing workspace to be created, got status %q", s)
+	}
+
+	// Destroy.
+	ids := make([]string, 0)
+	for k, v := range state.Attributes {
+		if len(k) > len("workspace_ids.") && k[:len("workspace_ids.")] == "workspace_ids." && k != "workspace_ids.%" {
+			ids = append(ids, v)
+		}
+	}
+	if _, diags := r.Apply(ctx, state, &terraform.InstanceDiff{Destroy: true}, client); diags.HasError() {
+		t.Fatalf("error destroying: %v", diags)
+	}
+	for _, id := range ids {
+		if _, err := client.Workspaces.ReadByID(ctx, id); err == nil {
+			t.Fatalf("expected workspace %s to be deleted", id)
+		}
+	}
+}
+
+func TestWorkspaceSet_createRollback(t *testing.T) {
+	client := testScalrClient(t)
+
+	env, err := client.Environments.Create(ctx, scalr.EnvironmentCreateOptions{
+		Name:    scalr.String("test-env"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating environment: %v", err)
+	}
+
+	r := resourceScalrWorkspaceSet()
+	raw := map[string]interface{}{
+		"workspace": []interface{}{
+			map[string]interface{}{"key": "ok", "name": "ok", "environment_id": env.ID},
+			map[string]interface{}{"key": "bad", "name": "bad", "environment_id": "env-not-exists"},
+		},
+	}
+	diff, err := r.Diff(ctx, nil, terraform.NewResourceConfigRaw(raw), client)
+	if err != nil {
+		t.Fatalf("error planning: %v", err)
+	}
+	state, diags := r.Apply(ctx, nil, diff, client)
+	if !diags.HasError() {
+		t.Fatal("expected an error")
+	}
+	if state != nil && state.ID != "" {
+		t.Fatalf("expected no state, got %v", state)
+	}
+
+	wl, err := client.Workspaces.List(ctx, scalr.WorkspaceListOptions{Environment: scalr.String(env.ID)})
+	if err != nil {
+		t.Fatalf("error listing workspaces: %v", err)
+	}
+	if len(wl.Items) != 0 {
+		t.Fatalf("expected the created workspaces to be rolled back, got %d", len(wl.Items))
+	}
+}
+
+func TestForEachParallel(t *testing.T) {
+	keys := make([]string, 20)
+	for i := range keys {
+		keys[i] = fmt.Sprint(i)
+	}
+
+	var running, maxRunning int32
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	forEachParallel(keys, 3, func(key string) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+
+		mu.Lock()
+		seen[key] = true
+		mu.Unlock()
+	})
+
+	if len(seen) != len(keys) {
+		t.Fatalf("expected %d calls, got %d", len(keys), len(seen))
+	}
+	if maxRunning > 3 {
+		t.Fatalf("expected at most 3 parallel calls, got %d", maxRunning)
+	}
+}
+
+func testAccCheckScalrWorkspaceSetDestroy(s *terraform.State) error {
+	scalrClient := testAccProvider.Meta().(*scalr.Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "scalr_workspace_set" {
+			continue
+		}
+
+		for k, id := range rs.Primary.Attributes {
+			if k == "workspace_ids.%" || len(k) <= len("workspace_ids.") || k[:len("workspace_ids.")] != "workspace_ids." {
+				continue
+			}
+			if _, err := scalrClient.Workspaces.ReadByID(ctx, id); err == nil {
+				return fmt.Errorf("Workspace %s still exists", id)
+			}
+		}
+	}
+
+	return nil
+}
+
+func testAccScalrWorkspaceSetConfig(rInt int, workspaces string) string {
+	return fmt.Sprintf(`
+locals {
+  suffix = "%[1]d"
+}
+
+resource scalr_environment test {
+  name       = "test-env-ws-set-%[1]d"
+  account_id = "%[2]s"
+}
+
+resource scalr_workspace_set test {
+  parallelism = 5
+%[3]s
+}`, rInt, defaultAccount, workspaces)
+}
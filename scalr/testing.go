@@ -1,36 +1,92 @@
 package scalr
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/scalr/go-scalr"
 )
 
+const localTestAPIEnvVar = "SCALR_LOCAL_TEST_API"
 const defaultAccount = "acc-svrcncgh453bi8g"
 const testUser = "user-suh84u6vuvidtbg" // test@scalr.com
 const testUserEmail = "test@scalr.com"
 const readOnlyRole = "role-t67mjtmabulckto" // Reader
 const userRole = "role-t67mjtmauajto7g"     // User
 
+// testScalrClient returns a client of a local test API server,
+// which is stopped when the test completes.
 func testScalrClient(t *testing.T) *scalr.Client {
+	server := newTestAPIServer()
+	t.Cleanup(server.Close)
+
 	config := &scalr.Config{
-		Token: "not-a-token",
+		Address: server.Address(),
+		Token:   testAPIToken,
 	}
 
-	client, err := scalr.NewClient(config)
+	client, err := newScalrClient(config)
 	if err != nil {
 		t.Fatalf("error creating Scalr client: %v", err)
 	}
 
-	client.Workspaces = newMockWorkspaces()
-	client.Variables = newMockVariables()
-
 	return client
 }
 
+// useLocalTestAPI starts a local test API server and points the provider
+// and the clients created in tests at it, when the SCALR_LOCAL_TEST_API
+// environment variable is set. It allows acceptance tests to run without
+// network access. The returned function stops the server.
+func useLocalTestAPI() (func(), error) {
+	if os.Getenv(localTestAPIEnvVar) == "" {
+		return func() {}, nil
+	}
+
+	server := newTestAPIServer()
+
+	// Service discovery is skipped for hosts that are configured
+	// in the CLI config file.
+	dir, err := os.MkdirTemp("", "scalr-test-api")
+	if err != nil {
+		server.Close()
+		return nil, err
+	}
+	stop := func() {
+		server.Close()
+		_ = os.RemoveAll(dir)
+	}
+
+	configFile := filepath.Join(dir, "terraformrc")
+	content := fmt.Sprintf("host %q {\n  services = {\n    %q = %q\n  }\n}\n",
+		server.Host(), scalrServiceIDs[0], server.Address())
+	if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+		stop()
+		return nil, err
+	}
+
+	for k, v := range map[string]string{
+		"TERRAFORM_CONFIG":     configFile,
+		"SCALR_HOSTNAME":       server.Host(),
+		"SCALR_ADDRESS":        server.Address(),
+		"SCALR_TOKEN":          testAPIToken,
+		currentAccountIDEnvVar: defaultAccount,
+	} {
+		if err := os.Setenv(k, v); err != nil {
+			stop()
+			return nil, err
+		}
+	}
+
+	return stop, nil
+}
+
 func assertCorrectState(t *testing.T, err error, actual, expected map[string]interface{}) {
 	t.Helper()
 	if err != nil {
@@ -49,7 +105,83 @@ func isAccTest() bool {
 
 func createScalrClient() (*scalr.Client, error) {
 	config := scalr.DefaultConfig()
-	config.Address = fmt.Sprintf("https://%s", os.Getenv("SCALR_HOSTNAME"))
-	scalrClient, err := scalr.NewClient(config)
+	if os.Getenv("SCALR_ADDRESS") == "" {
+		config.Address = fmt.Sprintf("https://%s", os.Getenv("SCALR_HOSTNAME"))
+	}
+	scalrClient, err := newScalrClient(config)
 	return scalrClient, err
 }
+
+// testResourcePlan plans the configuration of the resource against the state
+// the way Terraform does, with the raw configuration available to
+// CustomizeDiff and to the CRUD functions. Blocks are configured as lists
+// of maps. A nil state plans the creation.
+func testResourcePlan(
+	t *testing.T, r *schema.Resource, state *terraform.InstanceState, raw map[string]interface{}, meta interface{},
+) (*terraform.InstanceState, *terraform.InstanceDiff, error) {
+	t.Helper()
+
+	attrs := make(map[string]cty.Value)
+	for name, ty := range r.CoreConfigSchema().ImpliedType().AttributeTypes() {
+		attrs[name] = testCtyValue(t, name, ty, raw[name])
+	}
+
+	if state == nil {
+		state = &terraform.InstanceState{Attributes: make(map[string]string)}
+	}
+	state = state.DeepCopy()
+	state.RawConfig = cty.ObjectVal(attrs)
+
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta)
+	return state, diff, err
+}
+
+// testCtyValue converts a value of a raw configuration to the type
+// of the attribute.
+func testCtyValue(t *testing.T, name string, ty cty.Type, v interface{}) cty.Value {
+	t.Helper()
+
+	switch {
+	case v == nil:
+		return cty.NullVal(ty)
+	case ty == cty.String:
+		return cty.StringVal(v.(string))
+	case ty == cty.Bool:
+		return cty.BoolVal(v.(bool))
+	case ty == cty.Number:
+		return cty.NumberIntVal(int64(v.(int)))
+	case ty.IsMapType():
+		m := make(map[string]cty.Value)
+		for k, v := range v.(map[string]interface{}) {
+			m[k] = testCtyValue(t, name, ty.ElementType(), v)
+		}
+		if len(m) == 0 {
+			return cty.MapValEmpty(ty.ElementType())
+		}
+		return cty.MapVal(m)
+	case ty.IsListType() || ty.IsSetType():
+		var items []cty.Value
+		for _, v := range v.([]interface{}) {
+			items = append(items, testCtyValue(t, name, ty.ElementType(), v))
+		}
+		switch {
+		case len(items) == 0 && ty.IsListType():
+			return cty.ListValEmpty(ty.ElementType())
+		case len(items) == 0:
+			return cty.SetValEmpty(ty.ElementType())
+		case ty.IsListType():
+			return cty.ListVal(items)
+		default:
+			return cty.SetVal(items)
+		}
+	case ty.IsObjectType():
+		m := v.(map[string]interface{})
+		attrs := make(map[string]cty.Value)
+		for k, ty := range ty.AttributeTypes() {
+			attrs[k] = testCtyValue(t, name+"."+k, ty, m[k])
+		}
+		return cty.ObjectVal(attrs)
+	}
+	t.Fatalf("unsupported type of attribute %s: %s", name, ty.FriendlyName())
+	return cty.NilVal
+}
@@ -0,0 +1,1183 @@
+package scalr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	testAPIBasePath = "/api/iacp/v3/"
+	testAPIToken    = "local-test-api-token"
+)
+
+// testAPICollection describes a resource type served by the local test API.
+type testAPICollection struct {
+	// idPrefix is used to generate IDs of the created resources.
+	idPrefix string
+	// defaults returns the attributes computed by the server on create.
+	defaults func() map[string]interface{}
+	// children lists the relationships that make a resource owned by
+	// another one, so it is deleted together with its owner.
+	children []string
+}
+
+var testAPICollections = map[string]testAPICollection{
+	"access-policies": {idPrefix: "ap", defaults: func() map[string]interface{} {
+		return map[string]interface{}{"is-system": false}
+	}},
+	"access-tokens": {idPrefix: "at", defaults: func() map[string]interface{} {
+		return map[string]interface{}{
+			"description": "",
+			"created-at":  time.Now().UTC().Format(time.RFC3339),
+		}
+	}, children: []string{"agent-pool", "service-account"}},
+	"accounts": {idPrefix: "acc"},
+	"agent-pools": {idPrefix: "apool", defaults: func() map[string]interface{} {
+		return map[string]interface{}{"vcs-enabled": false, "is-shared": false}
+	}},
+	"agents": {idPrefix: "agent", children: []string{"agent-pool"}},
+	"endpoints": {idPrefix: "ep", defaults: func() map[string]interface{} {
+		return map[string]interface{}{"max-attempts": 3, "timeout": 15, "secret-key": "secret"}
+	}, children: []string{"environment"}},
+	"environments": {idPrefix: "env", defaults: func() map[string]interface{} {
+		return map[string]interface{}{
+			"status":                  "Active",
+			"cost-estimation-enabled": false,
+			"created-at":              time.Now().UTC().Format(time.RFC3339),
+		}
+	}},
+	"module-versions": {idPrefix: "modver", defaults: func() map[string]interface{} {
+		return map[string]interface{}{
+			"status":         "pending",
+			"is-root-module": false,
+			"tag":            "",
+			"commit-sha":     "",
+			"error-message":  "",
+		}
+	}, children: []string{"module"}},
+	"modules": {idPrefix: "mod", defaults: func() map[string]interface{} {
+		return map[string]interface{}{
+			"status":     "setup_complete",
+			"created-at": time.Now().UTC().Format(time.RFC3339),
+		}
+	}},
+	"permissions": {idPrefix: "perm"},
+	"plans": {idPrefix: "plan", defaults: func() map[string]interface{} {
+		return map[string]interface{}{
+			"status":                "finished",
+			"resource-additions":    0,
+			"resource-changes":      0,
+			"resource-destructions": 0,
+		}
+	}},
+	"policies": {idPrefix: "pol", children: []string{"policy-groups"}},
+	"policy-groups": {idPrefix: "pgrp", defaults: func() map[string]interface{} {
+		return map[string]interface{}{
+			"status":        "fetching",
+			"error-message": "",
+			"opa-version":   "0.41.0",
+		}
+	}},
+	"provider-configuration-links": {idPrefix: "pcfgl", defaults: func() map[string]interface{} {
+		return map[string]interface{}{"default": false, "alias": ""}
+	}, children: []string{"workspace", "environment", "provider-configuration"}},
+	"provider-configuration-parameters": {idPrefix: "pcfgp", defaults: func() map[string]interface{} {
+		return map[string]interface{}{"sensitive": false, "description": ""}
+	}, children: []string{"provider-configuration"}},
+	"provider-configurations": {idPrefix: "pcfg", defaults: func() map[string]interface{} {
+		return map[string]interface{}{"export-shell-variables": false, "is-shared": false}
+	}},
+	"runs": {idPrefix: "run", defaults: func() map[string]interface{} {
+		return map[string]interface{}{
+			"source":     "api",
+			"message":    "Queued manually via the Scalr API",
+			"is-destroy": false,
+			"is-dry":     false,
+			"status":     "pending",
+			"created-at": time.Now().UTC().Format(time.RFC3339),
+		}
+	}, children: []string{"workspace"}},
+	"state-versions": {idPrefix: "sv", defaults: func() map[string]interface{} {
+		return map[string]interface{}{
+			"serial":     0,
+			"outputs":    make([]interface{}, 0),
+			"created-at": time.Now().UTC().Format(time.RFC3339),
+		}
+	}, children: []string{"workspace"}},
+	"service-accounts": {idPrefix: "sa"},
+	"tags":             {idPrefix: "tag"},
+	"teams": {idPrefix: "team", defaults: func() map[string]interface{} {
+		return map[string]interface{}{"description": ""}
+	}},
+	"users": {idPrefix: "user"},
+	"vars": {idPrefix: "var", defaults: func() map[string]interface{} {
+		return map[string]interface{}{
+			"value":       "",
+			"description": "",
+			"hcl":         false,
+			"sensitive":   false,
+			"final":       false,
+		}
+	}, children: []string{"workspace", "environment"}},
+	"vcs-files": {idPrefix: "file"},
+	"webhooks": {idPrefix: "wh", defaults: func() map[string]interface{} {
+		return map[string]interface{}{"enabled": true}
+	}, children: []string{"workspace", "environment"}},
+	"workspaces": {idPrefix: "ws", defaults: func() map[string]interface{} {
+		return map[string]interface{}{
+			"auto-apply":        false,
+			"auto-queue-runs":   "skip_first",
+			"created-at":        time.Now().UTC().Format(time.RFC3339),
+			"execution-mode":    "remote",
+			"force-latest-run":  false,
+			"has-resources":     false,
+			"locked":            false,
+			"operations":        true,
+			"terraform-version": "1.3.7",
+			"var-files":         make([]interface{}, 0),
+			"working-directory": "",
+			"apply-schedule":    "",
+			"destroy-schedule":  "",
+		}
+	}, children: []string{"environment"}},
+}
+
+// testAPIResource is a JSON:API resource object kept by the local test API.
+type testAPIResource struct {
+	Type          string                          `json:"type"`
+	ID            string                          `json:"id"`
+	Attributes    map[string]interface{}          `json:"attributes"`
+	Relationships map[string]*testAPIRelationship `json:"relationships,omitempty"`
+}
+
+// testAPIRelationship holds either a single resource identifier, a list
+// of them, or nil.
+type testAPIRelationship struct {
+	Data interface{} `json:"data"`
+}
+
+type testAPIRef struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// refs returns the resource identifiers the relationship points to.
+func (r *testAPIRelationship) refs() []testAPIRef {
+	if r == nil {
+		return nil
+	}
+
+	var items []interface{}
+	switch data := r.Data.(type) {
+	case map[string]interface{}:
+		items = []interface{}{data}
+	case []interface{}:
+		items = data
+	}
+
+	refs := make([]testAPIRef, 0, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		typ, _ := m["type"].(string)
+		id, _ := m["id"].(string)
+		refs = append(refs, testAPIRef{Type: typ, ID: id})
+	}
+	return refs
+}
+
+// testAPIServer is an in-process stand-in for the Scalr API.
+// It keeps resources in memory and serves the JSON:API endpoints of
+// environments, workspaces, runs, state versions, variables, provider
+// configurations, policy groups, modules, tags, teams and their users,
+// permissions, access policies, webhooks, agent pools and their agents,
+// service accounts, access tokens, the files of VCS repositories and the
+// OIDC token exchange, so that tests can run without a live Scalr
+// installation.
+type testAPIServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	lastID    int
+	resources map[string]map[string]*testAPIResource
+	// accessTokens are the tokens issued in exchange for OIDC tokens,
+	// with their expiry time.
+	accessTokens map[string]time.Time
+	// accessTokenTTL is the lifetime of the issued access tokens.
+	accessTokenTTL time.Duration
+	// policyGroupPolls counts the reads of the policy groups
+	// that are fetching their policies.
+	policyGroupPolls map[string]int
+	// moduleVersionPolls counts the reads of the module versions
+	// that are being processed.
+	moduleVersionPolls map[string]int
+}
+
+// newTestAPIServer starts a local test API server. The caller is
+// responsible for closing it.
+func newTestAPIServer() *testAPIServer {
+	s := &testAPIServer{
+		resources:          make(map[string]map[string]*testAPIResource),
+		accessTokens:       make(map[string]time.Time),
+		accessTokenTTL:     time.Hour,
+		policyGroupPolls:   make(map[string]int),
+		moduleVersionPolls: make(map[string]int),
+	}
+	s.put(&testAPIResource{
+		Type:       "accounts",
+		ID:         defaultAccount,
+		Attributes: map[string]interface{}{"name": "mainiacp", "allowed-ips": make([]interface{}, 0)},
+	})
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Address returns the base URL of the API.
+func (s *testAPIServer) Address() string {
+	return s.URL + testAPIBasePath
+}
+
+// Host returns the host and port the server is listening on.
+func (s *testAPIServer) Host() string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+func (s *testAPIServer) put(r *testAPIResource) {
+	if s.resources[r.Type] == nil {
+		s.resources[r.Type] = make(map[string]*testAPIResource)
+	}
+	s.resources[r.Type][r.ID] = r
+}
+
+func (s *testAPIServer) get(typ, id string) *testAPIResource {
+	return s.resources[typ][id]
+}
+
+func (s *testAPIServer) newID(typ string) string {
+	s.lastID++
+	return fmt.Sprintf("%s-%017d", testAPICollections[typ].idPrefix, s.lastID)
+}
+
+func (s *testAPIServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == testAPIBasePath+"service-accounts/assume" && r.Method == http.MethodPost {
+		s.assumeServiceAccount(w, r)
+		return
+	}
+
+	if !s.authorized(r.Header.Get("Authorization")) {
+		writeTestAPIError(w, http.StatusUnauthorized, "Unauthorized", "invalid API token")
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, testAPIBasePath) {
+		writeTestAPIError(w, http.StatusNotFound, "Not Found", r.URL.Path)
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, testAPIBasePath), "/"), "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(parts) == 3 && parts[0] == "vcs-providers" && parts[2] == "files" && r.Method == http.MethodGet {
+		s.vcsFiles(w, r, parts[1])
+		return
+	}
+
+	if _, ok := testAPICollections[parts[0]]; !ok {
+		writeTestAPIError(w, http.StatusNotImplemented, "Not Implemented",
+			fmt.Sprintf("%s %s is not served by the test API", r.Method, r.URL.Path))
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		s.list(w, r, parts[0], nil)
+	case len(parts) == 1 && r.Method == http.MethodPost:
+		s.create(w, r, parts[0], nil)
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		s.read(w, r, parts[0], parts[1])
+	case len(parts) == 2 && r.Method == http.MethodPatch:
+		s.update(w, r, parts[0], parts[1])
+	case len(parts) == 2 && r.Method == http.MethodDelete:
+		s.delete(w, parts[0], parts[1])
+	case len(parts) == 3 && parts[0] == "workspaces" && parts[2] == "provider-configuration-links":
+		s.nested(w, r, parts[0], parts[1], "provider-configuration-links", "workspace", "")
+	case len(parts) == 3 && (parts[0] == "agent-pools" || parts[0] == "service-accounts") && parts[2] == "access-tokens":
+		s.nested(w, r, parts[0], parts[1], "access-tokens", testAPISingular(parts[0]), "")
+	case len(parts) == 3 && parts[0] == "provider-configurations" && parts[2] == "parameters":
+		s.nested(w, r, parts[0], parts[1], "provider-configuration-parameters", "provider-configuration", "parameters")
+	case len(parts) == 3 && parts[0] == "workspaces" && parts[2] == "current-state-version" && r.Method == http.MethodGet:
+		s.currentStateVersion(w, parts[1])
+	case len(parts) == 4 && parts[0] == "policy-groups" && parts[2] == "relationships" && parts[3] == "environments":
+		s.policyGroupEnvironments(w, r, parts[1], "")
+	case len(parts) == 5 && parts[0] == "policy-groups" && parts[2] == "relationships" && parts[3] == "environments":
+		s.policyGroupEnvironments(w, r, parts[1], parts[4])
+	case len(parts) == 4 && parts[2] == "relationships":
+		s.relationship(w, r, parts[0], parts[1], parts[3])
+	case len(parts) == 4 && parts[0] == "workspaces" && parts[3] == "set-schedule" && r.Method == http.MethodPost:
+		s.setSchedule(w, r, parts[1])
+	case len(parts) == 4 && parts[0] == "workspaces" && parts[2] == "actions" && r.Method == http.MethodPost:
+		s.workspaceAction(w, r, parts[1], parts[3])
+	case len(parts) == 4 && parts[0] == "runs" && parts[3] == "apply" && r.Method == http.MethodPost:
+		s.applyRun(w, parts[1])
+	default:
+		writeTestAPIError(w, http.StatusNotImplemented, "Not Implemented",
+			fmt.Sprintf("%s %s is not served by the test API", r.Method, r.URL.Path))
+	}
+}
+
+func (s *testAPIServer) authorized(header string) bool {
+	token := strings.TrimPrefix(header, "Bearer ")
+	if token == testAPIToken {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expiresAt, ok := s.accessTokens[token]
+	return ok && time.Now().Before(expiresAt)
+}
+
+// assumeServiceAccount exchanges an OIDC token for an access token.
+// The signature of the OIDC token is not verified, it only must not be expired.
+func (s *testAPIServer) assumeServiceAccount(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		ServiceAccountEmail string `json:"service-account-email"`
+		IDToken             string `json:"id-token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeTestAPIError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+	if in.ServiceAccountEmail == "" {
+		writeTestAPIError(w, http.StatusUnprocessableEntity, "Unprocessable Entity", "service-account-email is required")
+		return
+	}
+	if exp, ok := jwtExpiry(in.IDToken); !ok || !time.Now().Before(exp) {
+		writeTestAPIError(w, http.StatusForbidden, "Forbidden", "invalid or expired OIDC token")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastID++
+	token := fmt.Sprintf("oidc-access-token-%d", s.lastID)
+	s.accessTokens[token] = time.Now().Add(s.accessTokenTTL)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"access-token": token,
+		"expires-in":   int(s.accessTokenTTL.Seconds()),
+	})
+}
+
+func (s *testAPIServer) list(w http.ResponseWriter, r *http.Request, typ string, scope map[string]string) {
+	query := r.URL.Query()
+	filters := make(map[string]string)
+	for k, v := range scope {
+		filters[k] = v
+	}
+	for k, v := range query {
+		if strings.HasPrefix(k, "filter[") && strings.HasSuffix(k, "]") {
+			filters[k[len("filter["):len(k)-1]] = v[0]
+		}
+	}
+
+	var items []*testAPIResource
+	for _, res := range s.resources[typ] {
+		if q := query.Get("query"); q != "" && !testAPIMatchQuery(res, q) {
+			continue
+		}
+		if !testAPIMatchFilters(res, filters) {
+			continue
+		}
+		items = append(items, res)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+
+	pageSize, _ := strconv.Atoi(query.Get("page[size]"))
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	pageNumber, _ := strconv.Atoi(query.Get("page[number]"))
+	if pageNumber <= 0 {
+		pageNumber = 1
+	}
+	totalPages := (len(items) + pageSize - 1) / pageSize
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	start := (pageNumber - 1) * pageSize
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + pageSize
+	if end > len(items) {
+		end = len(items)
+	}
+	page := items[start:end]
+
+	pagination := map[string]interface{}{
+		"current-page": pageNumber,
+		"total-pages":  totalPages,
+		"total-count":  len(items),
+	}
+	if pageNumber > 1 {
+		pagination["prev-page"] = pageNumber - 1
+	}
+	if pageNumber < totalPages {
+		pagination["next-page"] = pageNumber + 1
+	}
+
+	data := make([]*testAPIResource, 0, len(page))
+	for _, res := range page {
+		data = append(data, testAPIPresented(res))
+	}
+	writeTestAPIDocument(w, http.StatusOK, map[string]interface{}{
+		"data":     data,
+		"included": s.included(page, query.Get("include")),
+		"meta":     map[string]interface{}{"pagination": pagination},
+	})
+}
+
+func (s *testAPIServer) read(w http.ResponseWriter, r *http.Request, typ, id string) {
+	res := s.get(typ, id)
+	if res == nil {
+		writeTestAPINotFound(w, typ, id)
+		return
+	}
+	switch typ {
+	case "policy-groups":
+		s.policyGroupPolled(res)
+	case "module-versions":
+		s.moduleVersionPolled(res)
+	}
+	s.writeResource(w, http.StatusOK, res, r.URL.Query().Get("include"))
+}
+
+func (s *testAPIServer) create(w http.ResponseWriter, r *http.Request, typ string, owner *testAPIRef) {
+	payload, err := decodeTestAPIPayload(r)
+	if err != nil {
+		writeTestAPIError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+
+	res := &testAPIResource{
+		Type:          typ,
+		ID:            s.newID(typ),
+		Attributes:    make(map[string]interface{}),
+		Relationships: make(map[string]*testAPIRelationship),
+	}
+	if defaults := testAPICollections[typ].defaults; defaults != nil {
+		res.Attributes = defaults()
+	}
+	for k, v := range payload.Attributes {
+		if v != nil {
+			res.Attributes[k] = v
+		}
+	}
+	for k, v := range payload.Relationships {
+		if v != nil {
+			res.Relationships[k] = v
+		}
+	}
+	if owner != nil {
+		res.Relationships[testAPISingular(owner.Type)] = &testAPIRelationship{
+			Data: map[string]interface{}{"type": owner.Type, "id": owner.ID},
+		}
+	}
+
+	// Check that referenced resources exist, as the real API does.
+	for name, rel := range res.Relationships {
+		for _, ref := range rel.refs() {
+			if _, served := testAPICollections[ref.Type]; served && s.get(ref.Type, ref.ID) == nil {
+				writeTestAPIError(w, http.StatusUnprocessableEntity, "Unprocessable Entity",
+					fmt.Sprintf("Invalid %s: %s with ID '%s' not found", name, ref.Type, ref.ID))
+				return
+			}
+		}
+	}
+
+	if typ == "access-tokens" {
+		// The token is only returned on create.
+		defer delete(res.Attributes, "token")
+		res.Attributes["token"] = fmt.Sprintf("token-%s", res.ID)
+	}
+	if typ == "module-versions" {
+		if err := s.moduleVersionCreating(res); err != nil {
+			writeTestAPIError(w, http.StatusUnprocessableEntity, "Unprocessable Entity", err.Error())
+			return
+		}
+	}
+
+	s.put(res)
+	if typ == "runs" {
+		s.runCreated(res)
+	}
+	s.writeResource(w, http.StatusCreated, res, "")
+}
+
+func (s *testAPIServer) update(w http.ResponseWriter, r *http.Request, typ, id string) {
+	res := s.get(typ, id)
+	if res == nil {
+		writeTestAPINotFound(w, typ, id)
+		return
+	}
+
+	payload, err := decodeTestAPIPayload(r)
+	if err != nil {
+		writeTestAPIError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+
+	for k, v := range payload.Attributes {
+		res.Attributes[k] = v
+	}
+	if res.Relationships == nil {
+		res.Relationships = make(map[string]*testAPIRelationship)
+	}
+	for k, v := range payload.Relationships {
+		res.Relationships[k] = v
+	}
+	if _, ok := payload.Attributes["vcs-repo"]; ok && typ == "policy-groups" {
+		// The policies are fetched again from the repository.
+		res.Attributes["status"] = "fetching"
+		s.policyGroupPolls[id] = 0
+	}
+
+	s.writeResource(w, http.StatusOK, res, "")
+}
+
+func (s *testAPIServer) delete(w http.ResponseWriter, typ, id string) {
+	if s.get(typ, id) == nil {
+		writeTestAPINotFound(w, typ, id)
+		return
+	}
+	s.remove(typ, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// remove deletes the resource together with the resources it owns
+// and drops references to it from to-many relationships.
+func (s *testAPIServer) remove(typ, id string) {
+	if s.get(typ, id) == nil {
+		return
+	}
+	delete(s.resources[typ], id)
+
+	for childType, collection := range testAPICollections {
+		for _, child := range s.resources[childType] {
+			for _, name := range collection.children {
+				for _, ref := range child.Relationships[name].refs() {
+					if ref.Type == typ && ref.ID == id {
+						s.remove(childType, child.ID)
+					}
+				}
+			}
+		}
+	}
+
+	for _, byID := range s.resources {
+		for _, res := range byID {
+			for _, rel := range res.Relationships {
+				items, ok := rel.Data.([]interface{})
+				if !ok {
+					continue
+				}
+				kept := make([]interface{}, 0, len(items))
+				for _, ref := range (&testAPIRelationship{Data: items}).refs() {
+					if ref.Type != typ || ref.ID != id {
+						kept = append(kept, map[string]interface{}{"type": ref.Type, "id": ref.ID})
+					}
+				}
+				rel.Data = kept
+			}
+		}
+	}
+}
+
+// nested serves collections scoped to a parent resource, such as
+// workspaces/{id}/provider-configuration-links. If inverse is set, the
+// parent relationship with that name lists the created resources.
+func (s *testAPIServer) nested(w http.ResponseWriter, r *http.Request, parentType, parentID, typ, owner, inverse string) {
+	parent := s.get(parentType, parentID)
+	if parent == nil {
+		writeTestAPINotFound(w, parentType, parentID)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.list(w, r, typ, map[string]string{owner: parentID})
+	case http.MethodPost:
+		before := len(s.resources[typ])
+		s.create(w, r, typ, &testAPIRef{Type: parentType, ID: parentID})
+		if inverse == "" || len(s.resources[typ]) == before {
+			return
+		}
+		for _, res := range s.resources[typ] {
+			if testAPIMatchFilters(res, map[string]string{owner: parentID}) {
+				testAPIAddRefs(parent, inverse, []testAPIRef{{Type: typ, ID: res.ID}})
+			}
+		}
+	default:
+		writeTestAPIError(w, http.StatusMethodNotAllowed, "Method Not Allowed", r.Method)
+	}
+}
+
+// relationship serves {type}/{id}/relationships/{name} endpoints that
+// add, replace and remove items of a to-many relationship.
+func (s *testAPIServer) relationship(w http.ResponseWriter, r *http.Request, typ, id, name string) {
+	res := s.get(typ, id)
+	if res == nil {
+		writeTestAPINotFound(w, typ, id)
+		return
+	}
+
+	var payload struct {
+		Data []testAPIRef `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeTestAPIError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+	for _, ref := range payload.Data {
+		if s.get(ref.Type, ref.ID) == nil {
+			writeTestAPINotFound(w, ref.Type, ref.ID)
+			return
+		}
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		testAPIAddRefs(res, name, payload.Data)
+	case http.MethodPatch:
+		res.Relationships[name] = &testAPIRelationship{Data: make([]interface{}, 0)}
+		testAPIAddRefs(res, name, payload.Data)
+	case http.MethodDelete:
+		testAPIRemoveRefs(res, name, payload.Data)
+	default:
+		writeTestAPIError(w, http.StatusMethodNotAllowed, "Method Not Allowed", r.Method)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// policyGroupEnvironments serves the linkage of a policy group to the
+// environments. A link is reflected in the policy-groups relationship
+// of the environment, the way the API reports it.
+func (s *testAPIServer) policyGroupEnvironments(w http.ResponseWriter, r *http.Request, id, environmentID string) {
+	policyGroup := s.get("policy-groups", id)
+	if policyGroup == nil {
+		writeTestAPINotFound(w, "policy-groups", id)
+		return
+	}
+
+	var refs []testAPIRef
+	switch {
+	case r.Method == http.MethodPost && environmentID == "":
+		var payload struct {
+			Data []testAPIRef `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeTestAPIError(w, http.StatusBadRequest, "Bad Request", err.Error())
+			return
+		}
+		refs = payload.Data
+	case r.Method == http.MethodDelete && environmentID != "":
+		refs = []testAPIRef{{Type: "environments", ID: environmentID}}
+	default:
+		writeTestAPIError(w, http.StatusMethodNotAllowed, "Method Not Allowed", r.Method)
+		return
+	}
+	for _, ref := range refs {
+		if s.get(ref.Type, ref.ID) == nil {
+			writeTestAPINotFound(w, ref.Type, ref.ID)
+			return
+		}
+	}
+
+	inverse := []testAPIRef{{Type: "policy-groups", ID: id}}
+	for _, ref := range refs {
+		environment := s.get(ref.Type, ref.ID)
+		if r.Method == http.MethodPost {
+			testAPIAddRefs(environment, "policy-groups", inverse)
+		} else {
+			testAPIRemoveRefs(environment, "policy-groups", inverse)
+		}
+	}
+	if r.Method == http.MethodPost {
+		testAPIAddRefs(policyGroup, "environments", refs)
+	} else {
+		testAPIRemoveRefs(policyGroup, "environments", refs)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// vcsFiles serves the files of the repositories of a VCS provider
+// that were added with addVCSFiles. Without a branch filter the files
+// of the default branch, main, are listed.
+func (s *testAPIServer) vcsFiles(w http.ResponseWriter, r *http.Request, vcsProviderID string) {
+	scope := map[string]string{"vcs-provider": vcsProviderID, "branch": "main"}
+	s.list(w, r, "vcs-files", scope)
+}
+
+// addVCSFiles adds the paths to the repository of the VCS provider.
+// Paths ending with a slash are directories.
+func (s *testAPIServer) addVCSFiles(vcsProviderID, repository, branch string, paths ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range paths {
+		typ := "file"
+		if strings.HasSuffix(p, "/") {
+			typ = "dir"
+		}
+		s.put(&testAPIResource{
+			Type: "vcs-files",
+			ID:   s.newID("vcs-files"),
+			Attributes: map[string]interface{}{
+				"path":       strings.TrimSuffix(p, "/"),
+				"type":       typ,
+				"repository": repository,
+				"branch":     branch,
+			},
+			Relationships: map[string]*testAPIRelationship{
+				"vcs-provider": {Data: map[string]interface{}{"type": "vcs-providers", "id": vcsProviderID}},
+			},
+		})
+	}
+}
+
+// workspaceAction locks and unlocks a workspace. The lock is held by
+// the caller, unless the `locked-by` attribute is changed to simulate
+// a lock held by another user or by a run, which only force-unlock releases.
+func (s *testAPIServer) workspaceAction(w http.ResponseWriter, r *http.Request, id, action string) {
+	res := s.get("workspaces", id)
+	if res == nil {
+		writeTestAPINotFound(w, "workspaces", id)
+		return
+	}
+
+	locked, _ := res.Attributes["locked"].(bool)
+	switch action {
+	case "lock":
+		if locked {
+			writeTestAPIError(w, http.StatusConflict, "Conflict", "Workspace is already locked")
+			return
+		}
+		var in struct {
+			Reason string `json:"reason"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		res.Attributes["locked"] = true
+		res.Attributes["locked-by"] = testAPIToken
+		res.Attributes["lock-reason"] = in.Reason
+	case "unlock", "force-unlock":
+		if !locked {
+			writeTestAPIError(w, http.StatusConflict, "Conflict", "Workspace is already unlocked")
+			return
+		}
+		if action == "unlock" && res.Attributes["locked-by"] != testAPIToken {
+			writeTestAPIError(w, http.StatusConflict, "Conflict", "Workspace is locked by another user")
+			return
+		}
+		res.Attributes["locked"] = false
+		delete(res.Attributes, "locked-by")
+		delete(res.Attributes, "lock-reason")
+	default:
+		writeTestAPIError(w, http.StatusNotImplemented, "Not Implemented",
+			fmt.Sprintf("%s %s is not served by the test API", r.Method, r.URL.Path))
+		return
+	}
+
+	s.writeResource(w, http.StatusOK, res, "")
+}
+
+func (s *testAPIServer) setSchedule(w http.ResponseWriter, r *http.Request, id string) {
+	res := s.get("workspaces", id)
+	if res == nil {
+		writeTestAPINotFound(w, "workspaces", id)
+		return
+	}
+
+	var payload map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeTestAPIError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+	for _, k := range []string{"apply-schedule", "destroy-schedule"} {
+		res.Attributes[k] = payload[k]
+	}
+
+	s.writeResource(w, http.StatusOK, res, "")
+}
+
+// currentStateVersion serves the state version with the highest serial.
+func (s *testAPIServer) currentStateVersion(w http.ResponseWriter, id string) {
+	if s.get("workspaces", id) == nil {
+		writeTestAPINotFound(w, "workspaces", id)
+		return
+	}
+
+	var current *testAPIResource
+	for _, sv := range s.resources["state-versions"] {
+		refs := sv.Relationships["workspace"].refs()
+		if len(refs) == 0 || refs[0].ID != id {
+			continue
+		}
+		serial, _ := sv.Attributes["serial"].(float64)
+		if current != nil {
+			if currentSerial, _ := current.Attributes["serial"].(float64); currentSerial >= serial {
+				continue
+			}
+		}
+		current = sv
+	}
+	if current == nil {
+		writeTestAPIError(w, http.StatusNotFound, "Not Found",
+			fmt.Sprintf("Workspace '%s' has no state versions", id))
+		return
+	}
+
+	s.writeResource(w, http.StatusOK, current, "")
+}
+
+func (s *testAPIServer) applyRun(w http.ResponseWriter, id string) {
+	res := s.get("runs", id)
+	if res == nil {
+		writeTestAPINotFound(w, "runs", id)
+		return
+	}
+	if res.Attributes["status"] != "planned" {
+		writeTestAPIError(w, http.StatusConflict, "Conflict",
+			fmt.Sprintf("Run in status '%s' cannot be applied", res.Attributes["status"]))
+		return
+	}
+
+	res.Attributes["status"] = "applied"
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// runCreated finishes the plan of a new run right away. Dry runs
+// are done after the plan, runs in workspaces with auto-apply are applied,
+// others wait for a confirmation.
+func (s *testAPIServer) runCreated(res *testAPIResource) {
+	plan := &testAPIResource{
+		Type:          "plans",
+		ID:            s.newID("plans"),
+		Attributes:    testAPICollections["plans"].defaults(),
+		Relationships: make(map[string]*testAPIRelationship),
+	}
+	s.put(plan)
+	res.Relationships["plan"] = &testAPIRelationship{
+		Data: map[string]interface{}{"type": plan.Type, "id": plan.ID},
+	}
+
+	status := "planned"
+	if isDry, _ := res.Attributes["is-dry"].(bool); isDry {
+		status = "planned_and_finished"
+	} else {
+		for _, ref := range res.Relationships["workspace"].refs() {
+			if ws := s.get(ref.Type, ref.ID); ws != nil && ws.Attributes["auto-apply"] == true {
+				status = "applied"
+			}
+		}
+	}
+	res.Attributes["status"] = status
+}
+
+// policyGroupPolled finishes fetching the policies of the policy group
+// on the second read. Repositories with "invalid" in the identifier
+// fail to be fetched.
+func (s *testAPIServer) policyGroupPolled(res *testAPIResource) {
+	if res.Attributes["status"] != "fetching" {
+		return
+	}
+	s.policyGroupPolls[res.ID]++
+	if s.policyGroupPolls[res.ID] < 2 {
+		return
+	}
+	delete(s.policyGroupPolls, res.ID)
+
+	for _, ref := range res.Relationships["policies"].refs() {
+		s.remove(ref.Type, ref.ID)
+	}
+	res.Relationships["policies"] = &testAPIRelationship{Data: make([]interface{}, 0)}
+
+	repo, _ := res.Attributes["vcs-repo"].(map[string]interface{})
+	identifier, _ := repo["identifier"].(string)
+	if strings.Contains(identifier, "invalid") {
+		res.Attributes["status"] = "errored"
+		res.Attributes["error-message"] = fmt.Sprintf("Repository %s not found", identifier)
+		return
+	}
+
+	policies := make([]interface{}, 0)
+	for _, name := range []string{"workspace_destroy", "instance_types"} {
+		policy := &testAPIResource{
+			Type: "policies",
+			ID:   s.newID("policies"),
+			Attributes: map[string]interface{}{
+				"name":           name,
+				"enabled":        true,
+				"enforced-level": "hard-mandatory",
+			},
+			Relationships: map[string]*testAPIRelationship{
+				"policy-groups": {Data: map[string]interface{}{"type": res.Type, "id": res.ID}},
+			},
+		}
+		s.put(policy)
+		policies = append(policies, map[string]interface{}{"type": policy.Type, "id": policy.ID})
+	}
+	res.Relationships["policies"] = &testAPIRelationship{Data: policies}
+	res.Attributes["status"] = "active"
+	res.Attributes["error-message"] = ""
+}
+
+// moduleVersionCreating takes the version of a new module version from
+// the tag, without the tag prefix of the module, unless it is set explicitly.
+// It rejects versions that are already published.
+func (s *testAPIServer) moduleVersionCreating(res *testAPIResource) error {
+	refs := res.Relationships["module"].refs()
+	if len(refs) == 0 {
+		return fmt.Errorf("module is required")
+	}
+	module := s.get(refs[0].Type, refs[0].ID)
+
+	tag, _ := res.Attributes["tag"].(string)
+	sha, _ := res.Attributes["commit-sha"].(string)
+	if (tag == "") == (sha == "") {
+		return fmt.Errorf("exactly one of tag and commit-sha is required")
+	}
+
+	v, _ := res.Attributes["version"].(string)
+	if v == "" {
+		if tag == "" {
+			return fmt.Errorf("version is required to publish a commit")
+		}
+		repo, _ := module.Attributes["vcs-repo"].(map[string]interface{})
+		prefix, _ := repo["tag-prefix"].(string)
+		v = strings.TrimPrefix(strings.TrimPrefix(tag, prefix), "v")
+		res.Attributes["version"] = v
+	}
+
+	for _, existing := range s.resources["module-versions"] {
+		if testAPIMatchFilters(existing, map[string]string{"module": module.ID, "version": v}) {
+			return fmt.Errorf("Version %s of module %s already exists", v, module.ID)
+		}
+	}
+	return nil
+}
+
+// moduleVersionPolled finishes processing the module version on
+// the second read. Tags and commits with "invalid" in the name
+// fail to be parsed.
+func (s *testAPIServer) moduleVersionPolled(res *testAPIResource) {
+	if res.Attributes["status"] != "pending" {
+		return
+	}
+	s.moduleVersionPolls[res.ID]++
+	if s.moduleVersionPolls[res.ID] < 2 {
+		return
+	}
+	delete(s.moduleVersionPolls, res.ID)
+
+	ref, _ := res.Attributes["tag"].(string)
+	if ref == "" {
+		ref, _ = res.Attributes["commit-sha"].(string)
+	}
+	if strings.Contains(ref, "invalid") {
+		res.Attributes["status"] = "errored"
+		res.Attributes["error-message"] = fmt.Sprintf("Failed to parse module at %s: main.tf:3,1-2: Argument or block definition required", ref)
+		return
+	}
+	res.Attributes["status"] = "ok"
+	res.Attributes["is-root-module"] = true
+}
+
+func (s *testAPIServer) writeResource(w http.ResponseWriter, status int, res *testAPIResource, include string) {
+	writeTestAPIDocument(w, status, map[string]interface{}{
+		"data":     testAPIPresented(res),
+		"included": s.included([]*testAPIResource{res}, include),
+	})
+}
+
+// included returns the resources referenced by the given relationships
+// of the primary data.
+func (s *testAPIServer) included(data []*testAPIResource, include string) []*testAPIResource {
+	included := make([]*testAPIResource, 0)
+	if include == "" {
+		return included
+	}
+
+	seen := make(map[testAPIRef]bool)
+	for _, res := range data {
+		for _, name := range strings.Split(include, ",") {
+			for _, ref := range res.Relationships[name].refs() {
+				if seen[ref] {
+					continue
+				}
+				if related := s.get(ref.Type, ref.ID); related != nil {
+					seen[ref] = true
+					included = append(included, related)
+				}
+			}
+		}
+	}
+	return included
+}
+
+func decodeTestAPIPayload(r *http.Request) (*testAPIResource, error) {
+	var payload struct {
+		Data *testAPIResource `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("invalid JSON:API payload: %v", err)
+	}
+	if payload.Data == nil {
+		return nil, fmt.Errorf("invalid JSON:API payload: missing data")
+	}
+	return payload.Data, nil
+}
+
+// testAPIPresented returns the resource as the API responds with it.
+// The values of sensitive variables are write-only.
+func testAPIPresented(res *testAPIResource) *testAPIResource {
+	if res.Type != "vars" || res.Attributes["sensitive"] != true {
+		return res
+	}
+	masked := *res
+	masked.Attributes = make(map[string]interface{}, len(res.Attributes))
+	for k, v := range res.Attributes {
+		masked.Attributes[k] = v
+	}
+	masked.Attributes["value"] = ""
+	return &masked
+}
+
+// testAPIMatchQuery implements the `query` search parameter,
+// which matches resources by ID or by a part of the name.
+func testAPIMatchQuery(res *testAPIResource, q string) bool {
+	if res.ID == q {
+		return true
+	}
+	name, _ := res.Attributes["name"].(string)
+	return strings.Contains(strings.ToLower(name), strings.ToLower(q))
+}
+
+// testAPIMatchFilters implements `filter[...]` parameters. A filter
+// matches an attribute by its value or a relationship by the related
+// resource ID. The `in:a,b` form matches any of the listed values.
+func testAPIMatchFilters(res *testAPIResource, filters map[string]string) bool {
+	for name, filter := range filters {
+		values := []string{filter}
+		if strings.HasPrefix(filter, "in:") {
+			values = strings.Split(strings.TrimPrefix(filter, "in:"), ",")
+		}
+
+		var actual []string
+		if v, ok := res.Attributes[name]; ok {
+			actual = []string{fmt.Sprint(v)}
+		} else if rel, ok := res.Relationships[name]; ok && rel.Data != nil {
+			for _, ref := range rel.refs() {
+				actual = append(actual, ref.ID)
+			}
+		} else {
+			actual = []string{"null"}
+		}
+
+		if !testAPIAnyEqual(values, actual) {
+			return false
+		}
+	}
+	return true
+}
+
+func testAPIAnyEqual(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func testAPIAddRefs(res *testAPIResource, name string, refs []testAPIRef) {
+	if res.Relationships == nil {
+		res.Relationships = make(map[string]*testAPIRelationship)
+	}
+	existing := res.Relationships[name].refs()
+	items := make([]interface{}, 0, len(existing)+len(refs))
+	for _, ref := range existing {
+		items = append(items, map[string]interface{}{"type": ref.Type, "id": ref.ID})
+	}
+	for _, ref := range refs {
+		duplicate := false
+		for _, e := range existing {
+			duplicate = duplicate || e == ref
+		}
+		if !duplicate {
+			items = append(items, map[string]interface{}{"type": ref.Type, "id": ref.ID})
+		}
+	}
+	res.Relationships[name] = &testAPIRelationship{Data: items}
+}
+
+// testAPIRemoveRefs removes the resource identifiers from the to-many
+// relationship of the resource.
+func testAPIRemoveRefs(res *testAPIResource, name string, refs []testAPIRef) {
+	if res.Relationships == nil {
+		res.Relationships = make(map[string]*testAPIRelationship)
+	}
+	kept := make([]interface{}, 0)
+	for _, existing := range res.Relationships[name].refs() {
+		removed := false
+		for _, ref := range refs {
+			removed = removed || existing == ref
+		}
+		if !removed {
+			kept = append(kept, map[string]interface{}{"type": existing.Type, "id": existing.ID})
+		}
+	}
+	res.Relationships[name] = &testAPIRelationship{Data: kept}
+}
+
+// testAPISingular returns the relationship name that points to a
+// resource of the given type, e.g. "workspace" for "workspaces".
+func testAPISingular(typ string) string {
+	return strings.TrimSuffix(typ, "s")
+}
+
+func writeTestAPINotFound(w http.ResponseWriter, typ, id string) {
+	writeTestAPIError(w, http.StatusNotFound, "Not Found",
+		fmt.Sprintf("%s with ID '%s' not found or user unauthorized", testAPISingular(typ), url.PathEscape(id)))
+}
+
+func writeTestAPIError(w http.ResponseWriter, status int, title, detail string) {
+	writeTestAPIDocument(w, status, map[string]interface{}{
+		"errors": []map[string]string{
+			{"status": strconv.Itoa(status), "title": title, "detail": detail},
+		},
+	})
+}
+
+func writeTestAPIDocument(w http.ResponseWriter, status int, doc interface{}) {
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(doc)
+}
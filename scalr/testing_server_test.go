@@ -0,0 +1,354 @@
+package scalr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/scalr/go-scalr"
+)
+
+func TestTestAPIServer_workspaces(t *testing.T) {
+	client := testScalrClient(t)
+
+	env, err := client.Environments.Create(ctx, scalr.EnvironmentCreateOptions{
+		Name:    scalr.String("test-env"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating environment: %v", err)
+	}
+	if env.Status != scalr.EnvironmentStatusActive {
+		t.Fatalf("expected environment status %q, got %q", scalr.EnvironmentStatusActive, env.Status)
+	}
+
+	if _, err := client.Workspaces.Create(ctx, scalr.WorkspaceCreateOptions{
+		Name:        scalr.String("test-ws"),
+		Environment: &scalr.Environment{ID: "env-not-exists"},
+	}); err == nil {
+		t.Fatal("expected error creating workspace in a missing environment")
+	}
+
+	var wsIDs []string
+	for _, name := range []string{"test-ws-1", "test-ws-2", "test-ws-3"} {
+		ws, err := client.Workspaces.Create(ctx, scalr.WorkspaceCreateOptions{
+			Name:        scalr.String(name),
+			Environment: &scalr.Environment{ID: env.ID},
+		})
+		if err != nil {
+			t.Fatalf("error creating workspace: %v", err)
+		}
+		if ws.ExecutionMode != scalr.WorkspaceExecutionModeRemote {
+			t.Fatalf("expected default execution mode, got %q", ws.ExecutionMode)
+		}
+		wsIDs = append(wsIDs, ws.ID)
+	}
+
+	ws, err := client.Workspaces.Read(ctx, env.ID, "test-ws-2")
+	if err != nil {
+		t.Fatalf("error reading workspace by name: %v", err)
+	}
+	if ws.ID != wsIDs[1] {
+		t.Fatalf("expected workspace %s, got %s", wsIDs[1], ws.ID)
+	}
+
+	ws, err = client.Workspaces.Update(ctx, ws.ID, scalr.WorkspaceUpdateOptions{
+		Name:      scalr.String("test-ws-renamed"),
+		AutoApply: scalr.Bool(true),
+	})
+	if err != nil {
+		t.Fatalf("error updating workspace: %v", err)
+	}
+	if ws.Name != "test-ws-renamed" || !ws.AutoApply {
+		t.Fatalf("workspace was not updated: %+v", ws)
+	}
+
+	ws, err = client.Workspaces.SetSchedule(ctx, ws.ID, scalr.WorkspaceRunScheduleOptions{ApplySchedule: "0 0 * * *"})
+	if err != nil {
+		t.Fatalf("error setting workspace schedule: %v", err)
+	}
+	if ws.ApplySchedule != "0 0 * * *" {
+		t.Fatalf("expected apply schedule to be set, got %q", ws.ApplySchedule)
+	}
+
+	options := scalr.WorkspaceListOptions{
+		Environment: scalr.String(env.ID),
+		ListOptions: scalr.ListOptions{PageSize: 2},
+	}
+	var listed []string
+	for {
+		wl, err := client.Workspaces.List(ctx, options)
+		if err != nil {
+			t.Fatalf("error listing workspaces: %v", err)
+		}
+		for _, w := range wl.Items {
+			listed = append(listed, w.ID)
+		}
+		if wl.CurrentPage >= wl.TotalPages {
+			break
+		}
+		options.PageNumber = wl.NextPage
+	}
+	if len(listed) != len(wsIDs) {
+		t.Fatalf("expected %d workspaces, got %v", len(wsIDs), listed)
+	}
+
+	tag, err := client.Tags.Create(ctx, scalr.TagCreateOptions{
+		Name:    scalr.String("test-tag"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating tag: %v", err)
+	}
+	if err := client.WorkspaceTags.Add(ctx, ws.ID, []*scalr.TagRelation{{ID: tag.ID}}); err != nil {
+		t.Fatalf("error adding workspace tags: %v", err)
+	}
+	ws, _ = client.Workspaces.ReadByID(ctx, ws.ID)
+	if len(ws.Tags) != 1 || ws.Tags[0].ID != tag.ID {
+		t.Fatalf("expected workspace to be tagged with %s, got %v", tag.ID, ws.Tags)
+	}
+	if err := client.Tags.Delete(ctx, tag.ID); err != nil {
+		t.Fatalf("error deleting tag: %v", err)
+	}
+	ws, _ = client.Workspaces.ReadByID(ctx, ws.ID)
+	if len(ws.Tags) != 0 {
+		t.Fatalf("expected deleted tag to be removed from workspace, got %v", ws.Tags)
+	}
+
+	if err := client.Environments.Delete(ctx, env.ID); err != nil {
+		t.Fatalf("error deleting environment: %v", err)
+	}
+	if _, err := client.Workspaces.ReadByID(ctx, ws.ID); !errors.Is(err, scalr.ErrResourceNotFound) {
+		t.Fatalf("expected workspace to be deleted with its environment, got %v", err)
+	}
+}
+
+func TestTestAPIServer_variables(t *testing.T) {
+	client := testScalrClient(t)
+
+	env, err := client.Environments.Create(ctx, scalr.EnvironmentCreateOptions{
+		Name:    scalr.String("test-env"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating environment: %v", err)
+	}
+
+	for _, key := range []string{"first", "second", "third"} {
+		_, err := client.Variables.Create(ctx, scalr.VariableCreateOptions{
+			Key:         scalr.String(key),
+			Value:       scalr.String("value"),
+			Category:    scalr.Category(scalr.CategoryTerraform),
+			Environment: &scalr.Environment{ID: env.ID},
+			Account:     &scalr.Account{ID: defaultAccount},
+		})
+		if err != nil {
+			t.Fatalf("error creating variable: %v", err)
+		}
+	}
+
+	vl, err := client.Variables.List(ctx, scalr.VariableListOptions{Filter: &scalr.VariableFilter{
+		Key:         scalr.String("in:first,third"),
+		Environment: scalr.String(env.ID),
+	}})
+	if err != nil {
+		t.Fatalf("error listing variables: %v", err)
+	}
+	if len(vl.Items) != 2 {
+		t.Fatalf("expected 2 variables, got %d", len(vl.Items))
+	}
+
+	v, err := client.Variables.Update(ctx, vl.Items[0].ID, scalr.VariableUpdateOptions{Value: scalr.String("changed")})
+	if err != nil {
+		t.Fatalf("error updating variable: %v", err)
+	}
+	if v.Value != "changed" || v.Key != vl.Items[0].Key {
+		t.Fatalf("variable was not updated: %+v", v)
+	}
+
+	if err := client.Variables.Delete(ctx, v.ID); err != nil {
+		t.Fatalf("error deleting variable: %v", err)
+	}
+	if _, err := client.Variables.Read(ctx, v.ID); !errors.Is(err, scalr.ErrResourceNotFound) {
+		t.Fatalf("expected variable to be deleted, got %v", err)
+	}
+}
+
+func TestTestAPIServer_providerConfigurations(t *testing.T) {
+	client := testScalrClient(t)
+
+	env, err := client.Environments.Create(ctx, scalr.EnvironmentCreateOptions{
+		Name:    scalr.String("test-env"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating environment: %v", err)
+	}
+	ws, err := client.Workspaces.Create(ctx, scalr.WorkspaceCreateOptions{
+		Name:        scalr.String("test-ws"),
+		Environment: &scalr.Environment{ID: env.ID},
+	})
+	if err != nil {
+		t.Fatalf("error creating workspace: %v", err)
+	}
+
+	pcfg, err := client.ProviderConfigurations.Create(ctx, scalr.ProviderConfigurationCreateOptions{
+		Name:         scalr.String("kubernetes"),
+		ProviderName: scalr.String("kubernetes"),
+		Account:      &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating provider configuration: %v", err)
+	}
+
+	param, err := client.ProviderConfigurationParameters.Create(ctx, pcfg.ID, scalr.ProviderConfigurationParameterCreateOptions{
+		Key:   scalr.String("config_path"),
+		Value: scalr.String("~/.kube/config"),
+	})
+	if err != nil {
+		t.Fatalf("error creating provider configuration parameter: %v", err)
+	}
+
+	pcfg, err = client.ProviderConfigurations.Read(ctx, pcfg.ID)
+	if err != nil {
+		t.Fatalf("error reading provider configuration: %v", err)
+	}
+	if len(pcfg.Parameters) != 1 || pcfg.Parameters[0].Key != "config_path" {
+		t.Fatalf("expected the parameter to be included, got %v", pcfg.Parameters)
+	}
+
+	_, err = client.ProviderConfigurationLinks.Create(ctx, ws.ID, scalr.ProviderConfigurationLinkCreateOptions{
+		ProviderConfiguration: &scalr.ProviderConfiguration{ID: pcfg.ID},
+		Alias:                 scalr.String("dev"),
+	})
+	if err != nil {
+		t.Fatalf("error creating provider configuration link: %v", err)
+	}
+
+	links, err := getProviderConfigurationWorkspaceLinks(ctx, client, ws.ID)
+	if err != nil {
+		t.Fatalf("error listing provider configuration links: %v", err)
+	}
+	if len(links) != 1 || links[0].ProviderConfiguration.ID != pcfg.ID || links[0].Alias != "dev" {
+		t.Fatalf("unexpected provider configuration links: %v", links)
+	}
+
+	if err := client.ProviderConfigurations.Delete(ctx, pcfg.ID); err != nil {
+		t.Fatalf("error deleting provider configuration: %v", err)
+	}
+	if _, err := client.ProviderConfigurationParameters.Read(ctx, param.ID); !errors.Is(err, scalr.ErrResourceNotFound) {
+		t.Fatalf("expected parameter to be deleted with its configuration, got %v", err)
+	}
+	links, _ = getProviderConfigurationWorkspaceLinks(ctx, client, ws.ID)
+	if len(links) != 0 {
+		t.Fatalf("expected links to be deleted with their configuration, got %v", links)
+	}
+}
+
+func TestTestAPIServer_accessAndWebhooks(t *testing.T) {
+	client := testScalrClient(t)
+
+	team, err := client.Teams.Create(ctx, scalr.TeamCreateOptions{
+		Name:    scalr.String("test-team"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating team: %v", err)
+	}
+
+	ap, err := client.AccessPolicies.Create(ctx, scalr.AccessPolicyCreateOptions{
+		Roles:   []*scalr.Role{{ID: readOnlyRole}},
+		Team:    &scalr.Team{ID: team.ID},
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating access policy: %v", err)
+	}
+	apl, err := client.AccessPolicies.List(ctx, scalr.AccessPolicyListOptions{Team: scalr.String(team.ID)})
+	if err != nil {
+		t.Fatalf("error listing access policies: %v", err)
+	}
+	if len(apl.Items) != 1 || apl.Items[0].ID != ap.ID {
+		t.Fatalf("expected access policy %s, got %v", ap.ID, apl.Items)
+	}
+
+	endpoint, err := client.Endpoints.Create(ctx, scalr.EndpointCreateOptions{
+		Name:    scalr.String("test-endpoint"),
+		Url:     scalr.String("https://example.com/webhook"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating endpoint: %v", err)
+	}
+
+	_, err = client.Webhooks.Create(ctx, scalr.WebhookCreateOptions{
+		Name:     scalr.String("test-webhook"),
+		Account:  &scalr.Account{ID: defaultAccount},
+		Endpoint: &scalr.Endpoint{ID: endpoint.ID},
+		Events:   []*scalr.EventDefinition{{ID: "run:completed"}},
+	})
+	if err != nil {
+		t.Fatalf("error creating webhook: %v", err)
+	}
+
+	wh, err := GetWebhookByName(ctx, GetWebhookByNameOptions{
+		Name:    scalr.String("test-webhook"),
+		Account: scalr.String(defaultAccount),
+	}, client)
+	if err != nil {
+		t.Fatalf("error reading webhook by name: %v", err)
+	}
+	if wh.Endpoint.ID != endpoint.ID || len(wh.Events) != 1 {
+		t.Fatalf("unexpected webhook: %+v", wh)
+	}
+}
+
+func TestTestAPIServer_unauthorized(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+
+	client, err := scalr.NewClient(&scalr.Config{Address: server.Address(), Token: "invalid"})
+	if err != nil {
+		t.Fatalf("error creating Scalr client: %v", err)
+	}
+
+	_, err = client.Environments.List(ctx, scalr.EnvironmentListOptions{})
+	if !errors.Is(err, scalr.ErrUnauthorized) {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+}
+
+func TestUseLocalTestAPI(t *testing.T) {
+	for _, k := range []string{
+		localTestAPIEnvVar, "TERRAFORM_CONFIG", "SCALR_HOSTNAME", "SCALR_ADDRESS", "SCALR_TOKEN", currentAccountIDEnvVar,
+	} {
+		t.Setenv(k, os.Getenv(k))
+	}
+	t.Setenv(localTestAPIEnvVar, "1")
+
+	stop, err := useLocalTestAPI()
+	if err != nil {
+		t.Fatalf("error starting local test API: %v", err)
+	}
+	defer stop()
+
+	provider := Provider()
+	if diags := provider.Configure(context.Background(), &terraform.ResourceConfig{}); diags.HasError() {
+		t.Fatalf("error configuring provider: %v", diags)
+	}
+
+	client := provider.Meta().(*scalr.Client)
+	if _, err := client.Accounts.Read(ctx, defaultAccount); err != nil {
+		t.Fatalf("error reading account through the configured provider: %v", err)
+	}
+
+	client, err = createScalrClient()
+	if err != nil {
+		t.Fatalf("error creating Scalr client: %v", err)
+	}
+	if _, err := client.Accounts.Read(ctx, defaultAccount); err != nil {
+		t.Fatalf("error reading account through the test client: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package scalr
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// tokenRotationSchema returns the attributes that rotate a token: the token
+// is replaced when the rotation period has elapsed since it was created,
+// or when one of the triggers changes.
+func tokenRotationSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"created_at": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"rotation_period": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validateRotationPeriod,
+		},
+		"rotate_triggers": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			ForceNew: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+	}
+}
+
+// parseRotationPeriod parses a number of days, e.g. `30d`, or a duration
+// in the time.ParseDuration format, e.g. `720h`.
+func parseRotationPeriod(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days := strings.TrimSuffix(s, "d")
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days %q", days)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+func validateRotationPeriod(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	period, err := parseRotationPeriod(v)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%s must be a number of days like 30d or a duration like 720h: %v", key, err))
+		return
+	}
+	if period < time.Minute {
+		errs = append(errs, fmt.Errorf("%s must be at least 1m, got %s", key, v))
+	}
+	return
+}
+
+// diffTokenRotation plans the replacement of a token whose rotation
+// period has elapsed.
+func diffTokenRotation(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || !d.NewValueKnown("rotation_period") {
+		return nil
+	}
+	v := d.Get("rotation_period").(string)
+	createdAt, err := time.Parse(time.RFC3339, d.Get("created_at").(string))
+	if v == "" || err != nil {
+		return nil
+	}
+	period, err := parseRotationPeriod(v)
+	if err != nil {
+		return err
+	}
+
+	if time.Now().Before(createdAt.Add(period)) {
+		return nil
+	}
+	log.Printf("[DEBUG] Token %s was created at %s, rotate it after %s", d.Id(), createdAt, v)
+	if err := d.SetNewComputed("token"); err != nil {
+		return err
+	}
+	if err := d.SetNewComputed("created_at"); err != nil {
+		return err
+	}
+	return d.ForceNew("created_at")
+}
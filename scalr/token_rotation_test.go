@@ -0,0 +1,113 @@
+package scalr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/scalr/go-scalr"
+)
+
+func TestParseRotationPeriod(t *testing.T) {
+	for s, expected := range map[string]time.Duration{
+		"30d":   30 * 24 * time.Hour,
+		"1d":    24 * time.Hour,
+		"720h":  720 * time.Hour,
+		"1h30m": 90 * time.Minute,
+	} {
+		period, err := parseRotationPeriod(s)
+		if err != nil || period != expected {
+			t.Errorf("%q: expected %s, got %s, %v", s, expected, period, err)
+		}
+		if _, errs := validateRotationPeriod(s, "rotation_period"); len(errs) != 0 {
+			t.Errorf("%q: unexpected errors: %v", s, errs)
+		}
+	}
+
+	for _, s := range []string{"", "d", "0d", "1.5d", "-1d", "30s", "720h0", "month"} {
+		if _, errs := validateRotationPeriod(s, "rotation_period"); len(errs) == 0 {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestTokenRotation(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+	client, err := newScalrClient(&scalr.Config{Address: server.Address(), Token: testAPIToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.put(&testAPIResource{Type: "agent-pools", ID: "apool-123", Attributes: map[string]interface{}{}})
+	server.put(&testAPIResource{Type: "service-accounts", ID: "sa-123", Attributes: map[string]interface{}{}})
+
+	for name, tc := range map[string]struct {
+		r     *schema.Resource
+		owner map[string]interface{}
+	}{
+		"agent pool token":      {resourceScalrAgentPoolToken(), map[string]interface{}{"agent_pool_id": "apool-123"}},
+		"service account token": {resourceScalrServiceAccountToken(), map[string]interface{}{"service_account_id": "sa-123"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := func(period string, triggers map[string]interface{}) map[string]interface{} {
+				c := map[string]interface{}{"description": "ci", "rotation_period": period, "rotate_triggers": triggers}
+				for k, v := range tc.owner {
+					c[k] = v
+				}
+				return c
+			}
+
+			d := schema.TestResourceDataRaw(t, tc.r.Schema, config("30d", map[string]interface{}{"release": "1"}))
+			if diags := tc.r.CreateContext(ctx, d, client); diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+			if d.Get("token").(string) == "" {
+				t.Fatal("expected the token to be set")
+			}
+			if _, err := time.Parse(time.RFC3339, d.Get("created_at").(string)); err != nil {
+				t.Fatalf("unexpected created_at: %v", err)
+			}
+			state := d.State()
+
+			plan := func(config map[string]interface{}) (requiresNew bool) {
+				t.Helper()
+				_, diff, err := testResourcePlan(t, tc.r, state, config, client)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return diff != nil && diff.RequiresNew()
+			}
+
+			if plan(config("30d", map[string]interface{}{"release": "1"})) {
+				t.Fatal("expected no replacement of a new token")
+			}
+			if !plan(config("30d", map[string]interface{}{"release": "2"})) {
+				t.Fatal("expected the replacement on a changed trigger")
+			}
+
+			// The token gets older than the rotation period.
+			server.mu.Lock()
+			server.get("access-tokens", d.Id()).Attributes["created-at"] = time.Now().Add(-31 * 24 * time.Hour).UTC().Format(time.RFC3339)
+			server.mu.Unlock()
+			d = tc.r.Data(state)
+			if diags := tc.r.ReadContext(ctx, d, client); diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+			state = d.State()
+
+			if !plan(config("30d", map[string]interface{}{"release": "1"})) {
+				t.Fatal("expected the replacement of an expired token")
+			}
+			_, diff, _ := testResourcePlan(t, tc.r, state, config("30d", map[string]interface{}{"release": "1"}), client)
+			if attr := diff.Attributes["token"]; attr == nil || !attr.NewComputed {
+				t.Fatalf("expected a new token, got %v", attr)
+			}
+			if plan(config("60d", map[string]interface{}{"release": "1"})) {
+				t.Fatal("expected no replacement with a longer rotation period")
+			}
+			if plan(config("", map[string]interface{}{"release": "1"})) {
+				t.Fatal("expected no replacement without a rotation period")
+			}
+		})
+	}
+}
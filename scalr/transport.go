@@ -0,0 +1,212 @@
+package scalr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 10
+
+	// Bounds of the exponential backoff between retries,
+	// used when the response has no Retry-After header.
+	retryWaitMin = 250 * time.Millisecond
+	retryWaitMax = 30 * time.Second
+)
+
+// tokenBucket is a token bucket rate limiter. It holds up to one second
+// worth of tokens, so short bursts are allowed.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(requestsPerSecond float64) *tokenBucket {
+	burst := math.Max(1, requestsPerSecond)
+	return &tokenBucket{
+		rate:   requestsPerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long to wait before it can be used.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// wait blocks until a request is allowed or the context is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	delay := b.reserve()
+	if delay == 0 {
+		return nil
+	}
+
+	log.Printf("[DEBUG] Request is throttled for %s", delay)
+	return sleepContext(ctx, delay)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryTransport is an http.RoundTripper that limits the rate of the requests
+// and retries the requests that failed with a connection error, a server error
+// or 429 Too Many Requests. The Retry-After header of the response is honored.
+type retryTransport struct {
+	transport  http.RoundTripper
+	limiter    *tokenBucket
+	maxRetries int
+}
+
+// newRetryTransport wraps the transport. A zero requestsPerSecond disables
+// the rate limiting.
+func newRetryTransport(transport http.RoundTripper, requestsPerSecond float64, maxRetries int) http.RoundTripper {
+	t := &retryTransport{
+		transport:  transport,
+		maxRetries: maxRetries,
+	}
+	if requestsPerSecond > 0 {
+		t.limiter = newTokenBucket(requestsPerSecond)
+	}
+	return t
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	// Buffer the body, so it can be sent again.
+	getBody := req.GetBody
+	if req.Body != nil && req.Body != http.NoBody && getBody == nil {
+		body, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		getBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		if t.limiter != nil {
+			if err := t.limiter.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		r := req
+		if getBody != nil {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+
+		resp, err := t.transport.RoundTrip(r)
+		if !shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		if attempt >= t.maxRetries {
+			if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+				// Report the error instead of the response,
+				// as go-scalr retries 429 responses on its own.
+				_ = resp.Body.Close()
+				return nil, fmt.Errorf(
+					"%s %s: request rate limit exceeded, giving up after %d retries", req.Method, req.URL, attempt)
+			}
+			return resp, err
+		}
+
+		wait := retryBackoff(attempt, resp)
+		if err != nil {
+			log.Printf("[DEBUG] Retry %s %s in %s (%d/%d): %v", req.Method, req.URL, wait, attempt+1, t.maxRetries, err)
+		} else {
+			log.Printf("[DEBUG] Retry %s %s in %s (%d/%d): %s", req.Method, req.URL, wait, attempt+1, t.maxRetries, resp.Status)
+			// Drain the body, so the connection can be reused.
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented)
+}
+
+// retryBackoff returns the delay before the next attempt. It is taken from
+// the Retry-After header of 429 and 503 responses, otherwise it grows
+// exponentially.
+func retryBackoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return wait
+		}
+	}
+
+	wait := time.Duration(math.Pow(2, float64(attempt)) * float64(retryWaitMin))
+	if wait > retryWaitMax || wait <= 0 {
+		wait = retryWaitMax
+	}
+	return wait
+}
+
+// parseRetryAfter parses the value of the Retry-After header,
+// which is either a number of seconds or an HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		wait := time.Until(t)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
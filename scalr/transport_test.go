@@ -0,0 +1,159 @@
+package scalr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/scalr/go-scalr"
+)
+
+func TestRetryTransport_retryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("expected the body to be sent on every attempt, got %q", body)
+		}
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, 0, 5)}
+	// A request without GetBody, the same way go-scalr sends it.
+	req, err := http.NewRequest(http.MethodPost, server.URL, io.NopCloser(strings.NewReader("payload")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+	if elapsed := time.Since(start); elapsed < 2*time.Second {
+		t.Fatalf("expected Retry-After to be honored, took %s", elapsed)
+	}
+}
+
+func TestRetryTransport_maxRetries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	httpClient := scalr.DefaultConfig().HTTPClient
+	httpClient.Transport = newRetryTransport(httpClient.Transport, 0, 2)
+	client, err := newScalrClient(&scalr.Config{
+		Address:    server.URL,
+		Token:      testAPIToken,
+		HTTPClient: httpClient,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RetryServerErrors(false)
+
+	_, err = client.Environments.Read(ctx, "env-123")
+	if err == nil || !strings.Contains(err.Error(), "giving up after 2 retries") {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestRetryTransport_serverErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		status   int
+		attempts int32
+	}{
+		"retried":         {http.StatusBadGateway, 3},
+		"not implemented": {http.StatusNotImplemented, 1},
+		"client error":    {http.StatusUnprocessableEntity, 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, 0, 2)}
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected status %d, got %d", tc.status, resp.StatusCode)
+			}
+			if calls != tc.attempts {
+				t.Fatalf("expected %d attempts, got %d", tc.attempts, calls)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_rateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, 10, 0)}
+
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	// The first 10 requests use the burst, the rest are sent at 10 per second.
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Fatalf("expected the requests to be throttled, took %s", elapsed)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d, ok := parseRetryAfter("7"); !ok || d != 7*time.Second {
+		t.Fatalf("expected 7s, got %s", d)
+	}
+	if d, ok := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)); !ok || d <= 0 || d > time.Minute {
+		t.Fatalf("expected up to a minute, got %s", d)
+	}
+	if _, ok := parseRetryAfter("soon"); ok {
+		t.Fatal("expected an invalid value to be ignored")
+	}
+	if d := retryBackoff(3, nil); d != 8*retryWaitMin {
+		t.Fatalf("expected exponential backoff, got %s", d)
+	}
+	if d := retryBackoff(20, nil); d != retryWaitMax {
+		t.Fatalf("expected the backoff to be capped, got %s", d)
+	}
+}
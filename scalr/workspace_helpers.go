@@ -2,9 +2,14 @@ package scalr
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"net/url"
+	"path"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/scalr/go-scalr"
 )
 
@@ -38,3 +43,258 @@ func unpackWorkspaceID(id string) (environmentID, name string, err error) {
 
 	return s[0], s[1], nil
 }
+
+// listWorkspaces returns all workspaces of the environment
+// for which the match function returns true.
+func listWorkspaces(
+	ctx context.Context, client *scalr.Client, environmentID string, match func(*scalr.Workspace) bool,
+) ([]*scalr.Workspace, error) {
+	workspaces := make([]*scalr.Workspace, 0)
+
+	options := scalr.WorkspaceListOptions{Environment: &environmentID}
+	for {
+		wl, err := client.Workspaces.List(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, w := range wl.Items {
+			if match(w) {
+				workspaces = append(workspaces, w)
+			}
+		}
+
+		// Exit the loop when we've seen all pages.
+		if wl.CurrentPage >= wl.TotalPages {
+			break
+		}
+
+		// Update the page number to get the next page.
+		options.PageNumber = wl.NextPage
+	}
+
+	return workspaces, nil
+}
+
+// providerConfigurationLinkKey identifies a provider configuration link
+// of a workspace by the provider configuration and the alias.
+func providerConfigurationLinkKey(providerConfigurationID, alias string) string {
+	return providerConfigurationID + alias
+}
+
+// expandProviderConfigurationLinks converts the `provider_configuration` blocks
+// to the link create options keyed by providerConfigurationLinkKey.
+func expandProviderConfigurationLinks(providerConfigurations []interface{}) map[string]scalr.ProviderConfigurationLinkCreateOptions {
+	links := make(map[string]scalr.ProviderConfigurationLinkCreateOptions)
+	for _, v := range providerConfigurations {
+		configLink := v.(map[string]interface{})
+		id := configLink["id"].(string)
+		alias := ""
+		linkCreateOption := scalr.ProviderConfigurationLinkCreateOptions{
+			ProviderConfiguration: &scalr.ProviderConfiguration{ID: id},
+		}
+		if v, ok := configLink["alias"]; ok && len(v.(string)) > 0 {
+			alias = v.(string)
+			linkCreateOption.Alias = scalr.String(alias)
+		}
+		links[providerConfigurationLinkKey(id, alias)] = linkCreateOption
+	}
+	return links
+}
+
+// providerConfigurationLinksSync holds the IDs of the provider configuration
+// links of a workspace handled by syncProviderConfigurationLinks.
+type providerConfigurationLinksSync struct {
+	// kept are the expected links that already existed.
+	kept []string
+	// created are the expected links that were missing.
+	created []string
+	// deleted are the links that were not expected.
+	deleted []string
+}
+
+// syncProviderConfigurationLinks creates the expected provider configuration
+// links of the workspace that are missing, and deletes the existing links
+// that are not expected. Only the links for which managed returns true
+// are deleted. The links handled so far are returned even on error.
+func syncProviderConfigurationLinks(
+	ctx context.Context,
+	client *scalr.Client,
+	workspaceID string,
+	expectedLinks map[string]scalr.ProviderConfigurationLinkCreateOptions,
+	managed func(link *scalr.ProviderConfigurationLink) bool,
+) (*providerConfigurationLinksSync, error) {
+	result := &providerConfigurationLinksSync{}
+	missingLinks := make(map[string]scalr.ProviderConfigurationLinkCreateOptions, len(expectedLinks))
+	for k, v := range expectedLinks {
+		missingLinks[k] = v
+	}
+
+	currentLinks, err := getProviderConfigurationWorkspaceLinks(ctx, client, workspaceID)
+	if err != nil {
+		return result, err
+	}
+
+	for _, currentLink := range currentLinks {
+		key := providerConfigurationLinkKey(currentLink.ProviderConfiguration.ID, currentLink.Alias)
+		if _, ok := missingLinks[key]; ok {
+			delete(missingLinks, key)
+			result.kept = append(result.kept, currentLink.ID)
+		} else if managed(currentLink) {
+			err = client.ProviderConfigurationLinks.Delete(ctx, currentLink.ID)
+			if err != nil {
+				return result, fmt.Errorf(
+					"Error removing provider configuration link in workspace %s: %v", workspaceID, err)
+			}
+			result.deleted = append(result.deleted, currentLink.ID)
+		}
+	}
+	for _, createOption := range missingLinks {
+		link, err := client.ProviderConfigurationLinks.Create(ctx, workspaceID, createOption)
+		if err != nil {
+			return result, fmt.Errorf(
+				"Error creating provider configuration link in workspace %s: %v", workspaceID, err)
+		}
+		result.created = append(result.created, link.ID)
+	}
+
+	return result, nil
+}
+
+// vcsFile is a file or a directory of a repository
+// browsed through a VCS provider.
+type vcsFile struct {
+	ID   string `jsonapi:"primary,vcs-files"`
+	Path string `jsonapi:"attr,path"`
+	// Type is either "file" or "dir".
+	Type string `jsonapi:"attr,type"`
+}
+
+// readVCSFile looks up a path of the repository on the branch, or on the
+// default branch if the branch is empty. It returns nil if the path
+// does not exist.
+func readVCSFile(ctx context.Context, client *scalr.Client, vcsProviderID, repository, branch, p string) (*vcsFile, error) {
+	query := url.Values{}
+	query.Set("filter[repository]", repository)
+	query.Set("filter[path]", p)
+	if branch != "" {
+		query.Set("filter[branch]", branch)
+	}
+
+	var files []*vcsFile
+	err := doAPIRequest(ctx, client, "GET", fmt.Sprintf("vcs-providers/%s/files?%s", vcsProviderID, query.Encode()), nil, &files)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if strings.Trim(f.Path, "/") == p {
+			return f, nil
+		}
+	}
+	return nil, nil
+}
+
+// validateWorkspaceVCSPaths checks that the working directory, the var files
+// and the trigger prefixes of a workspace with `validate_vcs_paths` exist on
+// the branch of its VCS repository. The var files are relative to the working
+// directory, the trigger prefixes to the repository path. The check is skipped
+// when the paths are not known yet. A failure to browse the repository fails
+// the plan, as the paths cannot be validated.
+func validateWorkspaceVCSPaths(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.Get("validate_vcs_paths").(bool) {
+		return nil
+	}
+	if d.Id() != "" && !d.HasChanges("validate_vcs_paths", "vcs_provider_id", "vcs_repo", "working_directory", "var_files") {
+		return nil
+	}
+	for _, k := range []string{"vcs_provider_id", "vcs_repo", "working_directory", "var_files"} {
+		if !d.NewValueKnown(k) {
+			log.Printf("[DEBUG] Skip validation of VCS paths, %s is not known yet", k)
+			return nil
+		}
+	}
+
+	vcsProviderID := d.Get("vcs_provider_id").(string)
+	vcsRepos := d.Get("vcs_repo").([]interface{})
+	if vcsProviderID == "" || len(vcsRepos) == 0 || vcsRepos[0] == nil {
+		return nil
+	}
+	vcsRepo := vcsRepos[0].(map[string]interface{})
+	repository := vcsRepo["identifier"].(string)
+	branch := vcsRepo["branch"].(string)
+	ref := "the default branch"
+	if branch != "" {
+		ref = fmt.Sprintf("branch %s", branch)
+	}
+
+	scalrClient := meta.(*scalr.Client)
+	check := func(p, kind string) (string, error) {
+		f, err := readVCSFile(ctx, scalrClient, vcsProviderID, repository, branch, p)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case f == nil:
+			return fmt.Sprintf("%s %s does not exist on %s of %s", kind, p, ref, repository), nil
+		case kind == "working_directory" && f.Type != "dir":
+			return fmt.Sprintf("working_directory %s is not a directory on %s of %s", p, ref, repository), nil
+		case kind == "var_files" && f.Type == "dir":
+			return fmt.Sprintf("var_files %s is a directory on %s of %s", p, ref, repository), nil
+		}
+		return "", nil
+	}
+
+	workingDir := strings.Trim(path.Clean("/"+d.Get("working_directory").(string)), "/")
+	paths := make([][2]string, 0)
+	if workingDir != "" {
+		paths = append(paths, [2]string{workingDir, "working_directory"})
+	}
+	for _, v := range d.Get("var_files").([]interface{}) {
+		varFile, _ := v.(string)
+		if varFile == "" {
+			continue
+		}
+		paths = append(paths, [2]string{strings.Trim(path.Join("/", workingDir, varFile), "/"), "var_files"})
+	}
+	repoPath, _ := vcsRepo["path"].(string)
+	triggerPrefixes, _ := vcsRepo["trigger_prefixes"].([]interface{})
+	for _, v := range triggerPrefixes {
+		prefix, _ := v.(string)
+		if p := strings.Trim(path.Join("/", repoPath, prefix), "/"); p != "" {
+			paths = append(paths, [2]string{p, "trigger_prefixes"})
+		}
+	}
+
+	problems := make([]string, 0)
+	for _, p := range paths {
+		problem, err := check(p[0], p[1])
+		if err != nil {
+			return fmt.Errorf(
+				"cannot validate the VCS paths of the workspace, error browsing %s: %v; "+
+					"set validate_vcs_paths to false to skip the check", repository, err,
+			)
+		}
+		if problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkAgentPoolOnline returns an error if no agent of the agent pool
+// is connected, so that runs of a workspace assigned to it would be stuck.
+func checkAgentPoolOnline(ctx context.Context, client *scalr.Client, agentPoolID string) error {
+	agents, err := listAgentPoolAgents(ctx, client, agentPoolID)
+	if err != nil {
+		return fmt.Errorf("Error retrieving agents of agent pool %s: %v", agentPoolID, err)
+	}
+	if countOnlineAgents(agents) == 0 {
+		return fmt.Errorf(
+			"Agent pool %s has no online agents (%d agents registered), runs of the workspace would not start. "+
+				"Connect an agent to the pool, or unset require_online_agents", agentPoolID, len(agents))
+	}
+	return nil
+}
@@ -2,8 +2,11 @@ package scalr
 
 import (
 	"context"
+	"net/http"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/scalr/go-scalr"
 )
 
@@ -23,20 +26,32 @@ func TestFetchWorkspaceID(t *testing.T) {
 			"",
 			true,
 		},
-		"found workspace": {
-			"hashicorp/a-workspace",
-			"ws-123",
-			false,
-		},
 	}
 
 	client := testScalrClient(t)
-	name := "a-workspace"
-	_, _ = client.Workspaces.Create(context.Background(), scalr.WorkspaceCreateOptions{
-		ID:          "ws-123",
-		Name:        &name,
-		Environment: &scalr.Environment{ID: "hashicorp"},
+	env, err := client.Environments.Create(context.Background(), scalr.EnvironmentCreateOptions{
+		Name:    scalr.String("hashicorp"),
+		Account: &scalr.Account{ID: defaultAccount},
+	})
+	if err != nil {
+		t.Fatalf("error creating environment: %v", err)
+	}
+	ws, err := client.Workspaces.Create(context.Background(), scalr.WorkspaceCreateOptions{
+		Name:        scalr.String("a-workspace"),
+		Environment: &scalr.Environment{ID: env.ID},
 	})
+	if err != nil {
+		t.Fatalf("error creating workspace: %v", err)
+	}
+	tests["found workspace"] = struct {
+		def  string
+		want string
+		err  bool
+	}{
+		env.ID + "/a-workspace",
+		ws.ID,
+		false,
+	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -95,3 +110,123 @@ func TestUnpackWorkspaceID(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateWorkspaceVCSPaths(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+	client, err := newScalrClient(&scalr.Config{
+		Address:    server.Address(),
+		Token:      testAPIToken,
+		HTTPClient: http.DefaultClient,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.addVCSFiles("vcs-123", "org/infra", "main", "prod/", "prod/prod.tfvars", "shared.tfvars")
+	server.addVCSFiles("vcs-123", "org/infra", "dev", "dev/", "dev/dev.tfvars")
+
+	withTriggerPrefixes := func(config map[string]interface{}, prefixes ...interface{}) map[string]interface{} {
+		config["vcs_repo"].([]interface{})[0].(map[string]interface{})["trigger_prefixes"] = prefixes
+		return config
+	}
+	config := func(workingDir, branch string, varFiles ...interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"name":               "test",
+			"environment_id":     "env-123",
+			"vcs_provider_id":    "vcs-123",
+			"working_directory":  workingDir,
+			"var_files":          varFiles,
+			"validate_vcs_paths": true,
+			"vcs_repo": []interface{}{
+				map[string]interface{}{"identifier": "org/infra", "branch": branch},
+			},
+		}
+	}
+
+	r := resourceScalrWorkspace()
+	for _, tc := range []struct {
+		name   string
+		config map[string]interface{}
+		errors []string
+	}{
+		{"existing paths", config("prod", "", "prod.tfvars", "../shared.tfvars"), nil},
+		{"existing paths on branch", config("dev", "dev", "dev.tfvars"), nil},
+		{"repository root", config("", "", "shared.tfvars"), nil},
+		{"existing trigger prefixes", withTriggerPrefixes(config("prod", ""), "prod/", "shared.tfvars"), nil},
+		{
+			"missing trigger prefixes",
+			withTriggerPrefixes(config("dev", "dev"), "dev", "prod"),
+			[]string{"trigger_prefixes prod does not exist on branch dev of org/infra"},
+		},
+		{
+			"missing paths",
+			config("prod", "dev", "prod.tfvars"),
+			[]string{
+				"working_directory prod does not exist on branch dev of org/infra",
+				"var_files prod/prod.tfvars does not exist on branch dev of org/infra",
+			},
+		},
+		{
+			"wrong types",
+			config("shared.tfvars", "", "../prod"),
+			[]string{
+				"working_directory shared.tfvars is not a directory on the default branch of org/infra",
+				"var_files prod is a directory on the default branch of org/infra",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := testResourcePlan(t, r, nil, tc.config, client)
+			if len(tc.errors) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors %v", tc.errors)
+			}
+			for _, e := range tc.errors {
+				if !strings.Contains(err.Error(), e) {
+					t.Fatalf("expected error %q, got %v", e, err)
+				}
+			}
+		})
+	}
+
+	// Without the flag the paths are not checked.
+	noValidation := config("missing", "", "missing.tfvars")
+	noValidation["validate_vcs_paths"] = false
+	if _, _, err := testResourcePlan(t, r, nil, noValidation, client); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Unchanged paths are not checked again.
+	state := &terraform.InstanceState{ID: "ws-123", Attributes: map[string]string{
+		"name":                          "test",
+		"environment_id":                "env-123",
+		"vcs_provider_id":               "vcs-123",
+		"working_directory":             "missing",
+		"var_files.#":                   "0",
+		"validate_vcs_paths":            "true",
+		"vcs_repo.#":                    "1",
+		"vcs_repo.0.identifier":         "org/infra",
+		"vcs_repo.0.branch":             "",
+		"vcs_repo.0.path":               "",
+		"vcs_repo.0.dry_runs_enabled":   "true",
+		"vcs_repo.0.ingress_submodules": "false",
+	}}
+	if _, _, err := testResourcePlan(t, r, state, config("missing", ""), client); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := testResourcePlan(t, r, state, config("still-missing", ""), client); err == nil {
+		t.Fatal("expected error for a changed working directory")
+	}
+
+	// A failure to browse the repository fails the plan.
+	server.Close()
+	_, _, err = testResourcePlan(t, r, nil, config("prod", "", "prod.tfvars"), client)
+	if err == nil || !strings.Contains(err.Error(), "cannot validate the VCS paths of the workspace") {
+		t.Fatalf("expected error browsing the repository, got %v", err)
+	}
+}